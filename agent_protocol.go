@@ -0,0 +1,48 @@
+package gitstr
+
+import "github.com/nbd-wtf/go-nostr"
+
+// The gitstr agent speaks a small newline-delimited JSON protocol over a
+// local unix socket, modeled on ssh-agent: a client connects, sends one
+// request per line, and reads back one response per line.
+
+type agentRequest struct {
+	Cmd string `json:"cmd"`
+
+	// ADD_KEY
+	Ncryptsec string `json:"ncryptsec,omitempty"`
+
+	// ADD_KEY (decryption password), LOCK (passphrase to set), UNLOCK
+	// (passphrase to check)
+	Password string `json:"password,omitempty"`
+
+	// SIGN_EVENT, REMOVE
+	Pubkey string `json:"pubkey,omitempty"`
+
+	// SIGN_EVENT
+	Event *nostr.Event `json:"event,omitempty"`
+}
+
+type agentResponse struct {
+	OK    bool   `json:"ok"`
+	Error string `json:"error,omitempty"`
+
+	// ADD_KEY
+	Pubkey string `json:"pubkey,omitempty"`
+
+	// LIST
+	Pubkeys []string `json:"pubkeys,omitempty"`
+
+	// SIGN_EVENT
+	ID  string `json:"id,omitempty"`
+	Sig string `json:"sig,omitempty"`
+}
+
+const (
+	agentCmdAddKey    = "ADD_KEY"
+	agentCmdList      = "LIST"
+	agentCmdSignEvent = "SIGN_EVENT"
+	agentCmdRemove    = "REMOVE"
+	agentCmdLock      = "LOCK"
+	agentCmdUnlock    = "UNLOCK"
+)
@@ -0,0 +1,58 @@
+//go:build !windows
+
+package gitstr
+
+import (
+	"fmt"
+	"net"
+	"os"
+	"path/filepath"
+	"syscall"
+
+	"golang.org/x/sys/unix"
+)
+
+// agentSocketPath follows the ssh-agent convention of living under
+// $XDG_RUNTIME_DIR, falling back to the system temp dir when that isn't
+// set.
+func agentSocketPath() string {
+	dir := os.Getenv("XDG_RUNTIME_DIR")
+	if dir == "" {
+		dir = os.TempDir()
+	}
+	return filepath.Join(dir, "gitstr-agent.sock")
+}
+
+func agentPidPath() string {
+	return agentSocketPath() + ".pid"
+}
+
+func agentListen(path string) (net.Listener, error) {
+	os.Remove(path)
+	return net.Listen("unix", path)
+}
+
+func agentDial(path string) (net.Conn, error) {
+	return net.Dial("unix", path)
+}
+
+// lockMemory is a best-effort attempt to keep a secret from being
+// swapped to disk. Failures are ignored: it's a hardening measure, not a
+// correctness requirement.
+func lockMemory(b []byte) {
+	if len(b) == 0 {
+		return
+	}
+	unix.Mlock(b)
+}
+
+func agentKill(pid int) error {
+	proc, err := os.FindProcess(pid)
+	if err != nil {
+		return err
+	}
+	if err := proc.Signal(syscall.SIGTERM); err != nil {
+		return fmt.Errorf("signaling agent process %d: %w", pid, err)
+	}
+	return nil
+}
@@ -0,0 +1,52 @@
+//go:build windows
+
+package gitstr
+
+import (
+	"net"
+	"os"
+	"path/filepath"
+	"unsafe"
+
+	winio "github.com/Microsoft/go-winio"
+	"golang.org/x/sys/windows"
+)
+
+// agentSocketPath is a Windows named pipe rather than a filesystem path:
+// there's no unix domain socket equivalent, so the agent listens on
+// \\.\pipe\gitstr-agent instead.
+func agentSocketPath() string {
+	return `\\.\pipe\gitstr-agent`
+}
+
+// agentPidPath still needs a real file, so it lives in the temp dir
+// rather than alongside the (non-filesystem) pipe path.
+func agentPidPath() string {
+	return filepath.Join(os.TempDir(), "gitstr-agent.pid")
+}
+
+func agentListen(path string) (net.Listener, error) {
+	return winio.ListenPipe(path, nil)
+}
+
+func agentDial(path string) (net.Conn, error) {
+	return winio.DialPipe(path, nil)
+}
+
+// lockMemory is a best-effort attempt to keep a secret from being
+// swapped to disk. Failures are ignored: it's a hardening measure, not a
+// correctness requirement.
+func lockMemory(b []byte) {
+	if len(b) == 0 {
+		return
+	}
+	windows.VirtualLock(uintptr(unsafe.Pointer(&b[0])), uintptr(len(b)))
+}
+
+func agentKill(pid int) error {
+	proc, err := os.FindProcess(pid)
+	if err != nil {
+		return err
+	}
+	return proc.Kill()
+}
@@ -0,0 +1,83 @@
+package gitstr
+
+import (
+	"bufio"
+	"encoding/json"
+	"fmt"
+	"net"
+
+	"github.com/nbd-wtf/go-nostr"
+)
+
+// agentRunning reports whether a gitstr agent is listening on the local
+// socket.
+func agentRunning() bool {
+	conn, err := agentDial(agentSocketPath())
+	if err != nil {
+		return false
+	}
+	conn.Close()
+	return true
+}
+
+// callAgent sends a single request to the running agent and returns its
+// response.
+func callAgent(req agentRequest) (agentResponse, error) {
+	conn, err := agentDial(agentSocketPath())
+	if err != nil {
+		return agentResponse{}, fmt.Errorf("connecting to agent: %w", err)
+	}
+	defer conn.Close()
+
+	return sendAgentRequest(conn, req)
+}
+
+func sendAgentRequest(conn net.Conn, req agentRequest) (agentResponse, error) {
+	data, err := json.Marshal(req)
+	if err != nil {
+		return agentResponse{}, err
+	}
+	data = append(data, '\n')
+	if _, err := conn.Write(data); err != nil {
+		return agentResponse{}, fmt.Errorf("writing to agent: %w", err)
+	}
+
+	scanner := bufio.NewScanner(conn)
+	scanner.Buffer(make([]byte, 0, 64*1024), 10*1024*1024)
+	if !scanner.Scan() {
+		if err := scanner.Err(); err != nil {
+			return agentResponse{}, fmt.Errorf("reading from agent: %w", err)
+		}
+		return agentResponse{}, fmt.Errorf("agent closed the connection without responding")
+	}
+
+	var resp agentResponse
+	if err := json.Unmarshal(scanner.Bytes(), &resp); err != nil {
+		return agentResponse{}, fmt.Errorf("parsing agent response: %w", err)
+	}
+	if resp.Error != "" {
+		return resp, fmt.Errorf("agent: %s", resp.Error)
+	}
+	return resp, nil
+}
+
+// agentSigner delegates signing to a key already loaded in a running
+// gitstr agent, identified by its public key.
+type agentSigner struct {
+	pubkey string
+}
+
+func (s *agentSigner) PublicKey() (string, error) {
+	return s.pubkey, nil
+}
+
+func (s *agentSigner) SignEvent(evt *nostr.Event) error {
+	resp, err := callAgent(agentRequest{Cmd: agentCmdSignEvent, Pubkey: s.pubkey, Event: evt})
+	if err != nil {
+		return err
+	}
+	evt.Sig = resp.Sig
+	evt.PubKey = s.pubkey
+	evt.ID = resp.ID
+	return nil
+}
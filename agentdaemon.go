@@ -0,0 +1,196 @@
+package gitstr
+
+import (
+	"bufio"
+	"crypto/sha256"
+	"encoding/json"
+	"fmt"
+	"net"
+	"sync"
+	"time"
+
+	"github.com/nbd-wtf/go-nostr"
+	"github.com/nbd-wtf/go-nostr/nip49"
+)
+
+// agentEntry is one secret key held by a running agent, evicted once ttl
+// has passed since it was last used. sec is kept as the exact []byte
+// passed to lockMemory, so the mlock actually covers the bytes retained
+// here rather than some other copy.
+type agentEntry struct {
+	sec     []byte
+	expires time.Time
+}
+
+// agentDaemon is the process started by `str agent`: it holds decrypted
+// secret keys in memory, for a limited time, and signs on behalf of
+// clients that connect to its socket, so a plaintext key never has to
+// touch disk or be typed more than once per session.
+type agentDaemon struct {
+	mu       sync.Mutex
+	keys     map[string]*agentEntry // pubkey -> entry
+	ttl      time.Duration
+	locked   bool
+	lockHash []byte
+}
+
+func newAgentDaemon(ttl time.Duration) *agentDaemon {
+	return &agentDaemon{
+		keys: map[string]*agentEntry{},
+		ttl:  ttl,
+	}
+}
+
+// Serve accepts connections on ln until it's closed, handling one
+// request per line on each connection.
+func (d *agentDaemon) Serve(ln net.Listener) error {
+	for {
+		conn, err := ln.Accept()
+		if err != nil {
+			return err
+		}
+		go d.handleConn(conn)
+	}
+}
+
+func (d *agentDaemon) handleConn(conn net.Conn) {
+	defer conn.Close()
+	scanner := bufio.NewScanner(conn)
+	scanner.Buffer(make([]byte, 0, 64*1024), 10*1024*1024)
+	enc := json.NewEncoder(conn)
+	for scanner.Scan() {
+		var req agentRequest
+		if err := json.Unmarshal(scanner.Bytes(), &req); err != nil {
+			enc.Encode(agentResponse{Error: fmt.Sprintf("invalid request: %s", err)})
+			continue
+		}
+		enc.Encode(d.handle(req))
+	}
+}
+
+func (d *agentDaemon) handle(req agentRequest) agentResponse {
+	switch req.Cmd {
+	case agentCmdAddKey:
+		return d.addKey(req)
+	case agentCmdList:
+		return d.list()
+	case agentCmdSignEvent:
+		return d.signEvent(req)
+	case agentCmdRemove:
+		return d.remove(req)
+	case agentCmdLock:
+		return d.lock(req)
+	case agentCmdUnlock:
+		return d.unlock(req)
+	default:
+		return agentResponse{Error: fmt.Sprintf("unknown command %q", req.Cmd)}
+	}
+}
+
+func (d *agentDaemon) addKey(req agentRequest) agentResponse {
+	if d.isLocked() {
+		return agentResponse{Error: "agent is locked"}
+	}
+
+	sec, err := nip49.Decrypt(req.Ncryptsec, req.Password)
+	if err != nil {
+		return agentResponse{Error: fmt.Sprintf("decrypting key: %s", err)}
+	}
+	pubkey, err := nostr.GetPublicKey(sec)
+	if err != nil {
+		return agentResponse{Error: fmt.Sprintf("deriving public key: %s", err)}
+	}
+
+	secBytes := []byte(sec)
+	lockMemory(secBytes)
+	d.mu.Lock()
+	d.keys[pubkey] = &agentEntry{sec: secBytes, expires: time.Now().Add(d.ttl)}
+	d.mu.Unlock()
+
+	return agentResponse{OK: true, Pubkey: pubkey}
+}
+
+func (d *agentDaemon) list() agentResponse {
+	if d.isLocked() {
+		return agentResponse{Error: "agent is locked"}
+	}
+
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	now := time.Now()
+	pubkeys := make([]string, 0, len(d.keys))
+	for pubkey, entry := range d.keys {
+		if now.After(entry.expires) {
+			delete(d.keys, pubkey)
+			continue
+		}
+		pubkeys = append(pubkeys, pubkey)
+	}
+	return agentResponse{OK: true, Pubkeys: pubkeys}
+}
+
+func (d *agentDaemon) signEvent(req agentRequest) agentResponse {
+	if d.isLocked() {
+		return agentResponse{Error: "agent is locked"}
+	}
+	if req.Event == nil {
+		return agentResponse{Error: "missing event"}
+	}
+
+	d.mu.Lock()
+	entry, ok := d.keys[req.Pubkey]
+	if ok {
+		if time.Now().After(entry.expires) {
+			delete(d.keys, req.Pubkey)
+			ok = false
+		} else {
+			entry.expires = time.Now().Add(d.ttl)
+		}
+	}
+	d.mu.Unlock()
+	if !ok {
+		return agentResponse{Error: "no such key loaded in agent"}
+	}
+
+	if err := req.Event.Sign(string(entry.sec)); err != nil {
+		return agentResponse{Error: fmt.Sprintf("signing: %s", err)}
+	}
+	return agentResponse{OK: true, ID: req.Event.ID, Sig: req.Event.Sig}
+}
+
+func (d *agentDaemon) remove(req agentRequest) agentResponse {
+	d.mu.Lock()
+	delete(d.keys, req.Pubkey)
+	d.mu.Unlock()
+	return agentResponse{OK: true}
+}
+
+func (d *agentDaemon) lock(req agentRequest) agentResponse {
+	hash := sha256.Sum256([]byte(req.Password))
+	d.mu.Lock()
+	d.locked = true
+	d.lockHash = hash[:]
+	d.mu.Unlock()
+	return agentResponse{OK: true}
+}
+
+func (d *agentDaemon) unlock(req agentRequest) agentResponse {
+	hash := sha256.Sum256([]byte(req.Password))
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	if !d.locked {
+		return agentResponse{OK: true}
+	}
+	if string(hash[:]) != string(d.lockHash) {
+		return agentResponse{Error: "wrong password"}
+	}
+	d.locked = false
+	d.lockHash = nil
+	return agentResponse{OK: true}
+}
+
+func (d *agentDaemon) isLocked() bool {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	return d.locked
+}
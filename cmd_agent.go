@@ -0,0 +1,165 @@
+package gitstr
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/nbd-wtf/go-nostr/nip19"
+	"github.com/nbd-wtf/go-nostr/nip49"
+	"github.com/urfave/cli/v3"
+)
+
+// agentPubkeyConfigKeyFor mirrors gitConfigKeyFor: it's where we remember
+// which pubkey a given identity expects the agent to hold, so
+// gatherSecretKeyOrBunker knows what to ask the agent for.
+func agentPubkeyConfigKeyFor(name string) string {
+	if name == "" || name == defaultIdentity {
+		return "str.agent-pubkey"
+	}
+	return "str.identity." + name + ".agent-pubkey"
+}
+
+func AgentCommand() *cli.Command {
+	return &cli.Command{
+		Name:  "agent",
+		Usage: "run a long-lived process that holds decrypted keys in memory and signs on request",
+		Flags: []cli.Flag{
+			&cli.DurationFlag{Name: "agent-timeout", Usage: "how long a key stays loaded since its last use", Value: 15 * time.Minute},
+		},
+		Action: func(ctx context.Context, c *cli.Command) error {
+			socketPath := agentSocketPath()
+			ln, err := agentListen(socketPath)
+			if err != nil {
+				return fmt.Errorf("starting agent socket: %w", err)
+			}
+			defer os.Remove(socketPath)
+
+			pidPath := agentPidPath()
+			if err := os.WriteFile(pidPath, []byte(strconv.Itoa(os.Getpid())), 0600); err != nil {
+				return fmt.Errorf("writing pid file: %w", err)
+			}
+			defer os.Remove(pidPath)
+
+			fmt.Printf("gitstr agent listening on %s\n", socketPath)
+			daemon := newAgentDaemon(c.Duration("agent-timeout"))
+			return daemon.Serve(ln)
+		},
+		Commands: []*cli.Command{
+			agentAddCommand(),
+			agentListCommand(),
+			agentKillCommand(),
+		},
+	}
+}
+
+func agentAddCommand() *cli.Command {
+	return &cli.Command{
+		Name:  "add",
+		Usage: "load a secret key into the running agent",
+		Flags: identityFlags(),
+		Action: func(ctx context.Context, c *cli.Command) error {
+			if !agentRunning() {
+				return fmt.Errorf("no gitstr agent is running (start one with `str agent`)")
+			}
+
+			sec, err := ask("input secret key (hex, nsec or ncryptsec): ", "", func(answer string) bool {
+				switch {
+				case strings.HasPrefix(answer, "ncryptsec1"):
+					return false
+				case strings.HasPrefix(answer, "nsec1"):
+					return false
+				default:
+					return len(answer) != 64
+				}
+			})
+			if err != nil {
+				return err
+			}
+
+			ncryptsec := sec
+			password := ""
+			if strings.HasPrefix(sec, "ncryptsec1") {
+				password, err = askPassword("password to decrypt it: ", nil)
+				if err != nil {
+					return err
+				}
+			} else {
+				if strings.HasPrefix(sec, "nsec1") {
+					_, hex, err := nip19.Decode(sec)
+					if err != nil {
+						return fmt.Errorf("invalid nsec: %w", err)
+					}
+					sec = hex.(string)
+				}
+				password, err = askPassword("password to protect it while it's in transit: ", nil)
+				if err != nil {
+					return err
+				}
+				ncryptsec, err = nip49.Encrypt(sec, password, 16, nip49.ClientDoesNotTrackThisData)
+				if err != nil {
+					return fmt.Errorf("encrypting key: %w", err)
+				}
+			}
+
+			resp, err := callAgent(agentRequest{Cmd: agentCmdAddKey, Ncryptsec: ncryptsec, Password: password})
+			if err != nil {
+				return err
+			}
+
+			identity, _, err := identityAndKeybase(c)
+			if err != nil {
+				return err
+			}
+			if _, err := git("config", "--local", agentPubkeyConfigKeyFor(identity), resp.Pubkey); err != nil {
+				return fmt.Errorf("remembering agent pubkey: %w", err)
+			}
+
+			npub, _ := nip19.EncodePublicKey(resp.Pubkey)
+			fmt.Printf("key loaded into agent for %s\n", npub)
+			return nil
+		},
+	}
+}
+
+func agentListCommand() *cli.Command {
+	return &cli.Command{
+		Name:  "list",
+		Usage: "list the public keys currently loaded in the running agent",
+		Action: func(ctx context.Context, c *cli.Command) error {
+			if !agentRunning() {
+				return fmt.Errorf("no gitstr agent is running")
+			}
+			resp, err := callAgent(agentRequest{Cmd: agentCmdList})
+			if err != nil {
+				return err
+			}
+			for _, pubkey := range resp.Pubkeys {
+				npub, _ := nip19.EncodePublicKey(pubkey)
+				fmt.Println(npub)
+			}
+			return nil
+		},
+	}
+}
+
+func agentKillCommand() *cli.Command {
+	return &cli.Command{
+		Name:  "kill",
+		Usage: "terminate the running agent",
+		Action: func(ctx context.Context, c *cli.Command) error {
+			data, err := os.ReadFile(agentPidPath())
+			if err != nil {
+				return fmt.Errorf("no gitstr agent pid file found: %w", err)
+			}
+			pid, err := strconv.Atoi(strings.TrimSpace(string(data)))
+			if err != nil {
+				return fmt.Errorf("invalid pid file: %w", err)
+			}
+			return agentKill(pid)
+		},
+	}
+}
@@ -6,10 +6,11 @@ import (
 	"fmt"
 	"os"
 	"os/exec"
-	"regexp"
 	"strings"
 	"time"
 
+	"github.com/Anderson-Juhasc/gitstr/internal/bip39"
+	"github.com/Anderson-Juhasc/gitstr/internal/render"
 	"github.com/chzyer/readline"
 	"github.com/fatih/color"
 	"github.com/nbd-wtf/go-nostr"
@@ -19,40 +20,98 @@ import (
 	"github.com/urfave/cli/v3"
 )
 
-var subjectRegex = regexp.MustCompile(`(?m)^Subject: (.*)$`)
+// formatFlag is shared by every command that renders a patch or
+// repository, so they all support --format the same way.
+func formatFlag() cli.Flag {
+	return &cli.StringFlag{Name: "format", Usage: "plain, color, json or mbox (defaults to color on a terminal, plain otherwise)"}
+}
+
+func resolveFormat(c *cli.Command) (render.Format, error) {
+	return render.ParseFormat(c.String("format"), !isStdoutPiped())
+}
 
 func isPiped() bool {
 	stat, _ := os.Stdin.Stat()
 	return stat.Mode()&os.ModeCharDevice == 0
 }
 
-func gatherSecretKeyOrBunker(ctx context.Context, c *cli.Command) (
-	bunker *nip46.BunkerClient,
-	key string,
-	encrypted bool,
-	err error,
-) {
+func isStdoutPiped() bool {
+	stat, _ := os.Stdout.Stat()
+	return stat.Mode()&os.ModeCharDevice == 0
+}
+
+// gatherSecretKeyOrBunker figures out, from flags, git config and (if
+// necessary) interactive prompts, which Signer the current command
+// should use: a Ledger hardware wallet, a NIP-46 bunker, a key already
+// loaded in a running gitstr agent, or a local secret key.
+func gatherSecretKeyOrBunker(ctx context.Context, c *cli.Command) (Signer, error) {
+	useLedger := c.Bool("ledger")
+	if !useLedger && !c.IsSet("ledger") {
+		ledgerCfg, _ := git("config", "--local", "str.ledger")
+		useLedger = ledgerCfg == "true"
+	}
+	if useLedger {
+		path := c.String("ledger-path")
+		if path == "" {
+			path, _ = git("config", "--local", "str.ledger.path")
+		}
+		if path == "" {
+			path = defaultLedgerPath
+		}
+		return &ledgerSigner{path: path}, nil
+	}
+
 	bunkerURL := c.String("connect")
 	if bunkerURL == "" {
 		bunkerURL, _ = git("config", "--local", "str.bunker", bunkerURL)
 	}
 	if bunkerURL != "" {
 		clientKey := nostr.GeneratePrivateKey()
-		bunker, err := nip46.ConnectBunker(ctx, clientKey, bunkerURL, nil)
-		if bunker != nil {
-			git("config", "--local", "str.bunker", bunkerURL)
+		bunker, err := nip46.ConnectBunker(ctx, clientKey, bunkerURL, nil, nil)
+		if err != nil {
+			return nil, err
+		}
+		git("config", "--local", "str.bunker", bunkerURL)
+		return &bunkerSigner{bunker: bunker}, nil
+	}
+
+	identity := c.String("as")
+	if identity == "" {
+		identity = defaultIdentity
+	}
+
+	if pubkey, _ := git("config", "--local", agentPubkeyConfigKeyFor(identity)); pubkey != "" && agentRunning() {
+		if resp, err := callAgent(agentRequest{Cmd: agentCmdList}); err == nil {
+			for _, loaded := range resp.Pubkeys {
+				if loaded == pubkey {
+					return &agentSigner{pubkey: pubkey}, nil
+				}
+			}
 		}
-		return bunker, "", false, err
+	}
+
+	backend := c.String("keyring-backend")
+	if backend == "" {
+		backend, _ = git("config", "--local", "str.keyring.backend")
+	}
+	kb, err := keybaseFor(backend)
+	if err != nil {
+		return nil, err
 	}
 
 	sec := c.String("sec")
 	if sec == "" && !c.IsSet("sec") {
-		sec, _ = git("config", "--local", "str.secretkey")
+		if signer, err := kb.Get(identity); err == nil {
+			return signer, nil
+		} else if err != ErrIdentityNotFound {
+			return nil, err
+		}
 	}
 
 	askToStore := false
+	isMnemonic := false
 	if sec == "" {
-		sec, _ = ask("input secret key (hex, nsec or ncryptsec): ", "", func(answer string) bool {
+		sec, _ = ask("input secret key (hex, nsec, ncryptsec or mnemonic): ", "", func(answer string) bool {
 			switch {
 			case nostr.IsValid32ByteHex(answer):
 				askToStore = true
@@ -63,37 +122,65 @@ func gatherSecretKeyOrBunker(ctx context.Context, c *cli.Command) (
 			case strings.HasPrefix(answer, "ncryptsec1"):
 				// will always store encrypted keys
 				return false
+			case bip39.IsMnemonic(answer):
+				isMnemonic = true
+				askToStore = true
+				return false
 			default:
 				return true
 			}
 		})
 		if sec == "" {
-			return nil, "", false, fmt.Errorf("couldn't gather secret key")
+			return nil, fmt.Errorf("couldn't gather secret key")
 		}
 	}
 
+	if isMnemonic {
+		passphrase := ""
+		if confirm("does this mnemonic have a passphrase? ") {
+			var err error
+			passphrase, err = askPassword("bip-39 passphrase: ", nil)
+			if err != nil {
+				return nil, err
+			}
+		}
+		derived, err := deriveNostrKey(sec, passphrase, 0)
+		if err != nil {
+			return nil, err
+		}
+		sec = derived
+	}
+
+	var signer Signer
 	if strings.HasPrefix(sec, "ncryptsec1") {
-		git("config", "--local", "str.secretkey", sec)
-		return nil, sec, true, nil
+		signer = &localSigner{sec: sec, encrypted: true}
+		// encrypted keys are always safe to store
+		if err := kb.Set(identity, signer); err != nil {
+			return nil, err
+		}
+		return signer, nil
 	} else {
 		if strings.HasPrefix(sec, "nsec1") {
 			_, hex, err := nip19.Decode(sec)
 			if err != nil {
-				return nil, "", false, fmt.Errorf("invalid nsec: %w", err)
+				return nil, fmt.Errorf("invalid nsec: %w", err)
 			}
 			sec = hex.(string)
 		}
 		if ok := nostr.IsValid32ByteHex(sec); !ok {
-			return nil, "", false, fmt.Errorf("invalid secret key")
+			return nil, fmt.Errorf("invalid secret key")
 		}
+		signer = &localSigner{sec: sec}
 	}
 
-	if (askToStore && confirm("store the secret key on git config? ")) ||
+	if (askToStore && confirm("store the secret key? ")) ||
 		c.Bool("store-sec") {
-		git("config", "--local", "str.secretkey", sec)
+		if err := kb.Set(identity, signer); err != nil {
+			return nil, err
+		}
 	}
 
-	return nil, sec, false, nil
+	return signer, nil
 }
 
 func getPatchRelays() []string {
@@ -135,34 +222,74 @@ func git(args ...string) (string, error) {
 	return strings.TrimSpace(string(v)), err
 }
 
-func sprintRepository(repo *nostr.Event) string {
-	res := ""
+// tagValues returns the values (everything after the tag name) of the
+// first tag matching key, following NIP-34's convention of packing
+// several values (e.g. several relay URLs) into one tag.
+func tagValues(tags nostr.Tags, key string) []string {
+	tag := tags.Find(key)
+	if len(tag) < 2 {
+		return nil
+	}
+	return tag[1:]
+}
+
+func sprintRepository(repo *nostr.Event, format render.Format) (string, error) {
 	npub, _ := nip19.EncodePublicKey(repo.PubKey)
-	res += "\nauthor: " + npub
-	res += "\nid: " + (*repo.Tags.GetFirst([]string{"d", ""}))[1]
-	res += "\n"
-	// TODO: more stuff
-	return res
+
+	r := &render.Repository{
+		ID:          repo.Tags.GetD(),
+		Author:      npub,
+		Name:        firstTagValue(repo.Tags, "name"),
+		Description: firstTagValue(repo.Tags, "description"),
+		Web:         tagValues(repo.Tags, "web"),
+		Clone:       tagValues(repo.Tags, "clone"),
+		Relays:      tagValues(repo.Tags, "relays"),
+	}
+	for _, pubkey := range tagValues(repo.Tags, "maintainers") {
+		maintainerNpub, err := nip19.EncodePublicKey(pubkey)
+		if err != nil {
+			maintainerNpub = pubkey
+		}
+		r.Maintainers = append(r.Maintainers, maintainerNpub)
+	}
+
+	var w strings.Builder
+	if err := render.WriteRepository(&w, r, format); err != nil {
+		return "", err
+	}
+	return w.String(), nil
 }
 
-func sprintPatch(patch *nostr.Event) string {
-	res := ""
+func firstTagValue(tags nostr.Tags, key string) string {
+	tag := tags.Find(key)
+	if len(tag) < 2 {
+		return ""
+	}
+	return tag[1]
+}
+
+func sprintPatch(patch *nostr.Event, format render.Format) (string, error) {
 	npub, _ := nip19.EncodePublicKey(patch.PubKey)
-	res += "\nid: " + patch.ID
-	res += "\nauthor: " + npub
 
+	meta := render.PatchMeta{
+		ID:     patch.ID,
+		Author: npub,
+	}
 	aTag := patch.Tags.GetFirst([]string{"a", ""})
 	if aTag != nil {
 		target := strings.Split((*aTag)[1], ":")
-		targetId := target[2]
 		targetNpub, _ := nip19.EncodePublicKey(target[1])
-		res += "\ntarget repo: " + targetId
-		res += "\ntarget author: " + targetNpub
+		meta.TargetRepoID = target[2]
+		meta.TargetAuthor = targetNpub
 	}
 
-	res += "\n\n" + patch.Content
-	// TODO: colors
-	return res
+	p := render.ParsePatch(patch.Content)
+
+	var w strings.Builder
+	if err := render.WritePatch(&w, meta, p, format); err != nil {
+		return "", err
+	}
+	return w.String(), nil
 }
 
 func humanDate(createdAt nostr.Timestamp) string {
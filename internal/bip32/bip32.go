@@ -0,0 +1,76 @@
+// Package bip32 implements just enough of BIP-32 hierarchical
+// deterministic key derivation to walk a secp256k1 private key down a
+// fixed derivation path, as used by NIP-06.
+package bip32
+
+import (
+	"crypto/hmac"
+	"crypto/sha512"
+	"fmt"
+	"math/big"
+
+	"github.com/btcsuite/btcd/btcec/v2"
+)
+
+var curveOrder = btcec.S256().N
+
+// Key is a node in the HD tree: a secp256k1 private key plus its chain
+// code.
+type Key struct {
+	Private   []byte // 32 bytes
+	ChainCode []byte // 32 bytes
+}
+
+// NewMasterKey derives the master key and chain code from a BIP-39 seed.
+func NewMasterKey(seed []byte) (*Key, error) {
+	mac := hmac.New(sha512.New, []byte("Bitcoin seed"))
+	mac.Write(seed)
+	I := mac.Sum(nil)
+	return &Key{Private: I[:32], ChainCode: I[32:]}, nil
+}
+
+// DeriveChild returns the child key at the given index. Indexes >=
+// 0x80000000 produce a hardened child.
+func (k *Key) DeriveChild(index uint32) (*Key, error) {
+	var data []byte
+	if index >= 0x80000000 {
+		data = append([]byte{0x00}, k.Private...)
+	} else {
+		_, pub := btcec.PrivKeyFromBytes(k.Private)
+		data = pub.SerializeCompressed()
+	}
+	data = append(data, byte(index>>24), byte(index>>16), byte(index>>8), byte(index))
+
+	mac := hmac.New(sha512.New, k.ChainCode)
+	mac.Write(data)
+	I := mac.Sum(nil)
+
+	il := new(big.Int).SetBytes(I[:32])
+	if il.Cmp(curveOrder) >= 0 {
+		return nil, fmt.Errorf("derived key is invalid, try a different index")
+	}
+
+	childKey := new(big.Int).Add(il, new(big.Int).SetBytes(k.Private))
+	childKey.Mod(childKey, curveOrder)
+	if childKey.Sign() == 0 {
+		return nil, fmt.Errorf("derived key is invalid, try a different index")
+	}
+
+	private := make([]byte, 32)
+	childKey.FillBytes(private)
+
+	return &Key{Private: private, ChainCode: I[32:]}, nil
+}
+
+// DerivePath walks a master key down a sequence of child indexes.
+func (k *Key) DerivePath(indexes []uint32) (*Key, error) {
+	current := k
+	for _, index := range indexes {
+		child, err := current.DeriveChild(index)
+		if err != nil {
+			return nil, err
+		}
+		current = child
+	}
+	return current, nil
+}
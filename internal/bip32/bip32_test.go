@@ -0,0 +1,64 @@
+package bip32
+
+import (
+	"encoding/hex"
+	"testing"
+)
+
+// TestVector1 checks derivation against BIP-32 test vector 1
+// (https://github.com/bitcoin/bips/blob/master/bip-0032.mediawiki),
+// comparing raw private keys rather than serialized xprv/xpub, since
+// this package doesn't implement that serialization.
+func TestVector1(t *testing.T) {
+	seed, _ := hex.DecodeString("000102030405060708090a0b0c0d0e0f")
+
+	master, err := NewMasterKey(seed)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if hex.EncodeToString(master.Private) != "e8f32e723decf4051aefac8e2c93c9c5b214313817cdb01a1494b917c8436b35" {
+		t.Fatalf("master private key mismatch: %s", hex.EncodeToString(master.Private))
+	}
+
+	hardenedChild, err := master.DeriveChild(0 + 0x80000000)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if hex.EncodeToString(hardenedChild.Private) != "edb2e14f9ee77d26dd93b4ecede8d16ed408ce149b6cd80b0715a2d911a0afea" {
+		t.Fatalf("m/0' private key mismatch: %s", hex.EncodeToString(hardenedChild.Private))
+	}
+
+	child, err := hardenedChild.DeriveChild(1)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if hex.EncodeToString(child.Private) != "3c6cb8d0f6a264c91ea8b5030fadaa8e538b020f0a387421a12de9319dc93368" {
+		t.Fatalf("m/0'/1 private key mismatch: %s", hex.EncodeToString(child.Private))
+	}
+}
+
+func TestDerivePath(t *testing.T) {
+	seed, _ := hex.DecodeString("000102030405060708090a0b0c0d0e0f")
+	master, err := NewMasterKey(seed)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	viaPath, err := master.DerivePath([]uint32{0 + 0x80000000, 1})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	step1, err := master.DeriveChild(0 + 0x80000000)
+	if err != nil {
+		t.Fatal(err)
+	}
+	step2, err := step1.DeriveChild(1)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if hex.EncodeToString(viaPath.Private) != hex.EncodeToString(step2.Private) {
+		t.Fatalf("DerivePath disagrees with manual DeriveChild calls")
+	}
+}
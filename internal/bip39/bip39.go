@@ -0,0 +1,132 @@
+// Package bip39 implements the BIP-39 mnemonic sentence scheme: turning
+// entropy into a human-memorable word list and back, and deriving the
+// PBKDF2 seed used for further key derivation (see NIP-06).
+package bip39
+
+import (
+	"crypto/rand"
+	"crypto/sha256"
+	"crypto/sha512"
+	_ "embed"
+	"fmt"
+	"math/big"
+	"strings"
+
+	"golang.org/x/crypto/pbkdf2"
+)
+
+//go:embed wordlist_english.txt
+var englishWordlistRaw string
+
+var englishWordlist = strings.Fields(englishWordlistRaw)
+
+var wordIndex = func() map[string]int {
+	m := make(map[string]int, len(englishWordlist))
+	for i, w := range englishWordlist {
+		m[w] = i
+	}
+	return m
+}()
+
+// NewEntropy returns `bits` bits of cryptographically secure randomness,
+// suitable for EntropyToMnemonic. `bits` must be 128 (12 words) or 256
+// (24 words).
+func NewEntropy(bits int) ([]byte, error) {
+	if bits != 128 && bits != 256 {
+		return nil, fmt.Errorf("entropy must be 128 or 256 bits, got %d", bits)
+	}
+	entropy := make([]byte, bits/8)
+	if _, err := rand.Read(entropy); err != nil {
+		return nil, fmt.Errorf("reading random entropy: %w", err)
+	}
+	return entropy, nil
+}
+
+// EntropyToMnemonic encodes entropy (16 or 32 bytes) as a 12- or 24-word
+// mnemonic sentence, appending checksum bits taken from the entropy's
+// SHA-256 hash as described in BIP-39.
+func EntropyToMnemonic(entropy []byte) (string, error) {
+	entropyBits := len(entropy) * 8
+	if entropyBits != 128 && entropyBits != 256 {
+		return "", fmt.Errorf("entropy must be 16 or 32 bytes, got %d", len(entropy))
+	}
+	checksumBits := entropyBits / 32
+
+	hash := sha256.Sum256(entropy)
+	bits := new(big.Int).SetBytes(entropy)
+	bits.Lsh(bits, uint(checksumBits))
+	bits.Or(bits, big.NewInt(int64(hash[0]>>(8-checksumBits))))
+
+	totalBits := entropyBits + checksumBits
+	numWords := totalBits / 11
+
+	words := make([]string, numWords)
+	mask := big.NewInt(0x7ff)
+	for i := numWords - 1; i >= 0; i-- {
+		index := new(big.Int).And(bits, mask).Int64()
+		words[i] = englishWordlist[index]
+		bits.Rsh(bits, 11)
+	}
+	return strings.Join(words, " "), nil
+}
+
+// MnemonicToEntropy validates a mnemonic's checksum and returns the
+// original entropy.
+func MnemonicToEntropy(mnemonic string) ([]byte, error) {
+	words := strings.Fields(mnemonic)
+	if len(words) != 12 && len(words) != 24 {
+		return nil, fmt.Errorf("mnemonic must have 12 or 24 words, got %d", len(words))
+	}
+
+	totalBits := len(words) * 11
+	bits := new(big.Int)
+	for _, w := range words {
+		idx, ok := wordIndex[w]
+		if !ok {
+			return nil, fmt.Errorf("%q is not in the BIP-39 english wordlist", w)
+		}
+		bits.Lsh(bits, 11)
+		bits.Or(bits, big.NewInt(int64(idx)))
+	}
+
+	checksumBits := totalBits / 33
+	entropyBits := totalBits - checksumBits
+
+	checksumMask := new(big.Int).Sub(new(big.Int).Lsh(big.NewInt(1), uint(checksumBits)), big.NewInt(1))
+	checksum := new(big.Int).And(bits, checksumMask).Int64()
+
+	entropy := new(big.Int).Rsh(bits, uint(checksumBits))
+	entropyBytes := make([]byte, entropyBits/8)
+	entropy.FillBytes(entropyBytes)
+
+	hash := sha256.Sum256(entropyBytes)
+	expected := int64(hash[0] >> (8 - checksumBits))
+	if checksum != expected {
+		return nil, fmt.Errorf("invalid mnemonic checksum")
+	}
+
+	return entropyBytes, nil
+}
+
+// IsMnemonic reports whether s looks like a 12- or 24-word BIP-39
+// mnemonic sentence (every word present in the english wordlist),
+// without validating its checksum.
+func IsMnemonic(s string) bool {
+	words := strings.Fields(s)
+	if len(words) != 12 && len(words) != 24 {
+		return false
+	}
+	for _, w := range words {
+		if _, ok := wordIndex[w]; !ok {
+			return false
+		}
+	}
+	return true
+}
+
+// SeedFromMnemonic derives the 64-byte BIP-39 seed from a mnemonic
+// sentence and an optional passphrase, via
+// PBKDF2-HMAC-SHA512(mnemonic, "mnemonic"+passphrase, 2048, 64).
+func SeedFromMnemonic(mnemonic, passphrase string) []byte {
+	return pbkdf2.Key([]byte(mnemonic), []byte("mnemonic"+passphrase), 2048, 64, sha512.New)
+}
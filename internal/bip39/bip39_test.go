@@ -0,0 +1,78 @@
+package bip39
+
+import (
+	"bytes"
+	"encoding/hex"
+	"testing"
+)
+
+func TestWordlistIsCanonical(t *testing.T) {
+	if len(englishWordlist) != 2048 {
+		t.Fatalf("wordlist has %d words, want 2048", len(englishWordlist))
+	}
+	if englishWordlist[0] != "abandon" || englishWordlist[2047] != "zoo" {
+		t.Fatalf("wordlist out of order: [0]=%q [2047]=%q", englishWordlist[0], englishWordlist[2047])
+	}
+}
+
+func TestEntropyToMnemonicRoundTrip(t *testing.T) {
+	for _, bits := range []int{128, 256} {
+		entropy, err := NewEntropy(bits)
+		if err != nil {
+			t.Fatal(err)
+		}
+		mnemonic, err := EntropyToMnemonic(entropy)
+		if err != nil {
+			t.Fatal(err)
+		}
+		got, err := MnemonicToEntropy(mnemonic)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if !bytes.Equal(got, entropy) {
+			t.Fatalf("round trip mismatch for %d bits", bits)
+		}
+	}
+}
+
+// TestEntropyToMnemonicAllFF exercises the top of the wordlist: for
+// all-0xff entropy every 11-bit group except the last (which carries the
+// checksum) is 0x7ff, so this is exactly the input that panicked with a
+// truncated wordlist.
+func TestEntropyToMnemonicAllFF(t *testing.T) {
+	entropy := bytes.Repeat([]byte{0xff}, 32)
+	mnemonic, err := EntropyToMnemonic(entropy)
+	if err != nil {
+		t.Fatal(err)
+	}
+	got, err := MnemonicToEntropy(mnemonic)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !bytes.Equal(got, entropy) {
+		t.Fatalf("round trip mismatch for all-0xff entropy")
+	}
+}
+
+// TestOfficialVector checks the zero-entropy vector from the BIP-39 test
+// vectors published in the reference implementation.
+func TestOfficialVector(t *testing.T) {
+	entropy, err := hex.DecodeString("0000000000000000000000000000000000000000000000000000000000000000")
+	if err != nil {
+		t.Fatal(err)
+	}
+	mnemonic, err := EntropyToMnemonic(entropy)
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := "abandon abandon abandon abandon abandon abandon abandon abandon abandon abandon abandon abandon abandon abandon abandon abandon abandon abandon abandon abandon abandon abandon abandon art"
+	if mnemonic != want {
+		t.Fatalf("got %q want %q", mnemonic, want)
+	}
+
+	seed := SeedFromMnemonic(mnemonic, "TREZOR")
+	wantSeed := "bda85446c68413707090a52022edd26a1c9462295029f2e60cd7c4f2bbd3097170af7a4d73245cafa9c3cca8d561a7c3de6f5d4a10be8ed2a5e608d68f92fcc8"
+	if hex.EncodeToString(seed) != wantSeed {
+		t.Fatalf("seed mismatch: got %s want %s", hex.EncodeToString(seed), wantSeed)
+	}
+}
@@ -0,0 +1,43 @@
+// Package render turns nostr patch and repository announcement events
+// into output for a terminal (plain or colorized), a machine (json), or
+// another tool in the git ecosystem (mbox, for piping into `git am`).
+package render
+
+import "fmt"
+
+// Format selects how a patch or repository is rendered.
+type Format int
+
+const (
+	// Plain renders as uncolored text.
+	Plain Format = iota
+	// Color renders as text with ANSI colors, for an interactive terminal.
+	Color
+	// JSON renders the parsed structure as machine-readable JSON.
+	JSON
+	// Mbox re-emits a patch as an RFC 2822 message suitable for `git am`.
+	// It's only meaningful for patches.
+	Mbox
+)
+
+// ParseFormat maps a --format flag value to a Format, defaulting to
+// Color when isTTY is true and Plain otherwise.
+func ParseFormat(s string, isTTY bool) (Format, error) {
+	switch s {
+	case "":
+		if isTTY {
+			return Color, nil
+		}
+		return Plain, nil
+	case "plain":
+		return Plain, nil
+	case "color":
+		return Color, nil
+	case "json":
+		return JSON, nil
+	case "mbox":
+		return Mbox, nil
+	default:
+		return 0, fmt.Errorf("unknown format %q (want plain, color, json or mbox)", s)
+	}
+}
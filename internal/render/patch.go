@@ -0,0 +1,175 @@
+package render
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+)
+
+var (
+	subjectRegex = regexp.MustCompile(`(?m)^Subject: (.*)$`)
+	fromRegex    = regexp.MustCompile(`(?m)^From: (.*)$`)
+	dateRegex    = regexp.MustCompile(`(?m)^Date: (.*)$`)
+	diffGitRegex = regexp.MustCompile(`^diff --git a/(.*) b/(.*)$`)
+	hunkRegex    = regexp.MustCompile(`^(@@ .* @@.*)$`)
+)
+
+// LineKind is the +/-/space prefix of one diff line.
+type LineKind int
+
+const (
+	Context LineKind = iota
+	Addition
+	Deletion
+)
+
+// Line is a single line of a hunk, with its prefix already stripped.
+type Line struct {
+	Kind LineKind
+	Text string
+}
+
+// Hunk is one `@@ ... @@` section of a file's diff.
+type Hunk struct {
+	Header string
+	Lines  []Line
+}
+
+// FileDiff is everything changed in one file.
+type FileDiff struct {
+	OldPath string
+	NewPath string
+	Hunks   []Hunk
+}
+
+// Patch is a git-format-patch email, parsed out of a NIP-34 patch
+// event's content.
+type Patch struct {
+	Subject string
+	From    string
+	Date    string
+	// Message is the prose cover-letter body between the Subject header
+	// and the diff: everything git-format-patch puts in the commit
+	// message, minus the "---" diffstat block it inserts before the
+	// actual diff.
+	Message string
+	Files   []FileDiff
+
+	// Body is the untouched original content, kept around so mbox can
+	// re-emit it verbatim and plain/color output can fall back to it
+	// when no diff could be parsed.
+	Body string
+}
+
+// ParsePatch reads the git-format-patch content of a NIP-34 patch event.
+// It's best-effort: a patch that doesn't look like a format-patch email
+// still comes back with Body set, just with empty metadata and no files.
+func ParsePatch(content string) *Patch {
+	p := &Patch{Body: content}
+
+	if m := subjectRegex.FindStringSubmatch(content); m != nil {
+		p.Subject = m[1]
+	}
+	if m := fromRegex.FindStringSubmatch(content); m != nil {
+		p.From = m[1]
+	}
+	if m := dateRegex.FindStringSubmatch(content); m != nil {
+		p.Date = m[1]
+	}
+
+	lines := strings.Split(content, "\n")
+	p.Message = extractMessage(lines)
+
+	var file *FileDiff
+	var hunk *Hunk
+	for _, line := range lines {
+		switch {
+		case diffGitRegex.MatchString(line):
+			m := diffGitRegex.FindStringSubmatch(line)
+			p.Files = append(p.Files, FileDiff{OldPath: m[1], NewPath: m[2]})
+			file = &p.Files[len(p.Files)-1]
+			hunk = nil
+
+		case hunkRegex.MatchString(line) && file != nil:
+			m := hunkRegex.FindStringSubmatch(line)
+			file.Hunks = append(file.Hunks, Hunk{Header: m[1]})
+			hunk = &file.Hunks[len(file.Hunks)-1]
+
+		case hunk != nil && len(line) > 0 && (line[0] == '+' || line[0] == '-' || line[0] == ' '):
+			kind := Context
+			switch line[0] {
+			case '+':
+				kind = Addition
+			case '-':
+				kind = Deletion
+			}
+			hunk.Lines = append(hunk.Lines, Line{Kind: kind, Text: line[1:]})
+		}
+	}
+
+	return p
+}
+
+// extractMessage pulls the cover-letter body out of a format-patch
+// email: everything after the last recognized header line, up to the
+// first "diff --git" line, with git-format-patch's own "---" diffstat
+// separator (and everything after it) dropped.
+func extractMessage(lines []string) string {
+	headerEnd := -1
+	for i, line := range lines {
+		if diffGitRegex.MatchString(line) {
+			break
+		}
+		if subjectRegex.MatchString(line) || fromRegex.MatchString(line) || dateRegex.MatchString(line) {
+			headerEnd = i
+		}
+	}
+	if headerEnd < 0 {
+		return ""
+	}
+
+	diffStart := len(lines)
+	for i := headerEnd + 1; i < len(lines); i++ {
+		if diffGitRegex.MatchString(lines[i]) {
+			diffStart = i
+			break
+		}
+	}
+
+	body := lines[headerEnd+1 : diffStart]
+	for i, line := range body {
+		if line == "---" {
+			body = body[:i]
+			break
+		}
+	}
+
+	return strings.Trim(strings.Join(body, "\n"), "\n")
+}
+
+// PatchMeta is the nostr-side context rendered alongside the parsed
+// patch: who sent it, and which repository it targets.
+type PatchMeta struct {
+	ID           string
+	Author       string // npub
+	TargetRepoID string
+	TargetAuthor string // npub, empty if the patch carries no "a" tag
+}
+
+func WritePatch(w *strings.Builder, meta PatchMeta, p *Patch, format Format) error {
+	switch format {
+	case Plain:
+		writePatchPlain(w, meta, p, false)
+		return nil
+	case Color:
+		writePatchPlain(w, meta, p, true)
+		return nil
+	case JSON:
+		return writePatchJSON(w, meta, p)
+	case Mbox:
+		writePatchMbox(w, p)
+		return nil
+	default:
+		return fmt.Errorf("unsupported patch format %v", format)
+	}
+}
@@ -0,0 +1,74 @@
+package render
+
+import (
+	"encoding/json"
+	"fmt"
+	"strings"
+)
+
+type jsonLine struct {
+	Kind string `json:"kind"` // "context", "addition" or "deletion"
+	Text string `json:"text"`
+}
+
+type jsonHunk struct {
+	Header string     `json:"header"`
+	Lines  []jsonLine `json:"lines"`
+}
+
+type jsonFileDiff struct {
+	OldPath string     `json:"oldPath"`
+	NewPath string     `json:"newPath"`
+	Hunks   []jsonHunk `json:"hunks"`
+}
+
+type jsonPatch struct {
+	ID           string         `json:"id"`
+	Author       string         `json:"author"`
+	TargetRepoID string         `json:"targetRepoId,omitempty"`
+	TargetAuthor string         `json:"targetAuthor,omitempty"`
+	Subject      string         `json:"subject,omitempty"`
+	From         string         `json:"from,omitempty"`
+	Date         string         `json:"date,omitempty"`
+	Message      string         `json:"message,omitempty"`
+	Files        []jsonFileDiff `json:"files,omitempty"`
+}
+
+func writePatchJSON(w *strings.Builder, meta PatchMeta, p *Patch) error {
+	out := jsonPatch{
+		ID:           meta.ID,
+		Author:       meta.Author,
+		TargetRepoID: meta.TargetRepoID,
+		TargetAuthor: meta.TargetAuthor,
+		Subject:      p.Subject,
+		From:         p.From,
+		Date:         p.Date,
+		Message:      p.Message,
+	}
+	for _, file := range p.Files {
+		jf := jsonFileDiff{OldPath: file.OldPath, NewPath: file.NewPath}
+		for _, hunk := range file.Hunks {
+			jh := jsonHunk{Header: hunk.Header}
+			for _, line := range hunk.Lines {
+				kind := "context"
+				switch line.Kind {
+				case Addition:
+					kind = "addition"
+				case Deletion:
+					kind = "deletion"
+				}
+				jh.Lines = append(jh.Lines, jsonLine{Kind: kind, Text: line.Text})
+			}
+			jf.Hunks = append(jf.Hunks, jh)
+		}
+		out.Files = append(out.Files, jf)
+	}
+
+	data, err := json.MarshalIndent(out, "", "  ")
+	if err != nil {
+		return fmt.Errorf("marshaling patch: %w", err)
+	}
+	w.Write(data)
+	w.WriteByte('\n')
+	return nil
+}
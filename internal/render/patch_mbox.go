@@ -0,0 +1,24 @@
+package render
+
+import (
+	"strings"
+	"time"
+)
+
+// writePatchMbox re-emits a patch as an RFC 2822 message suitable for
+// `git am`. NIP-34 patch content is already a git-format-patch email, so
+// this mostly passes Body through, adding the leading "From " mbox
+// separator line format-patch output has but a bare email doesn't.
+func writePatchMbox(w *strings.Builder, p *Patch) {
+	if !strings.HasPrefix(p.Body, "From ") {
+		date := p.Date
+		if date == "" {
+			date = time.Now().UTC().Format(time.ANSIC)
+		}
+		w.WriteString("From 0000000000000000000000000000000000000000 " + date + "\n")
+	}
+	w.WriteString(p.Body)
+	if !strings.HasSuffix(p.Body, "\n") {
+		w.WriteByte('\n')
+	}
+}
@@ -0,0 +1,77 @@
+package render
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/fatih/color"
+)
+
+func writePatchPlain(w *strings.Builder, meta PatchMeta, p *Patch, colorize bool) {
+	dim := plainString
+	bold := plainString
+	cyan := plainString
+	green := plainString
+	red := plainString
+	underline := plainString
+	if colorize {
+		dim = color.New(color.Faint).Sprint
+		bold = color.New(color.Bold).Sprint
+		cyan = color.New(color.FgCyan).Sprint
+		green = color.New(color.FgGreen).Sprint
+		red = color.New(color.FgRed).Sprint
+		underline = color.New(color.Underline).Sprint
+	}
+
+	fmt.Fprintln(w)
+	fmt.Fprintln(w, dim("id: "+meta.ID))
+	fmt.Fprintln(w, dim("author: "+meta.Author))
+	if meta.TargetRepoID != "" {
+		fmt.Fprintln(w, dim("target repo: "+meta.TargetRepoID))
+		fmt.Fprintln(w, dim("target author: "+meta.TargetAuthor))
+	}
+	if p.From != "" {
+		fmt.Fprintln(w, dim("from: "+p.From))
+	}
+	if p.Date != "" {
+		fmt.Fprintln(w, dim("date: "+p.Date))
+	}
+	if p.Subject != "" {
+		fmt.Fprintln(w)
+		fmt.Fprintln(w, bold(p.Subject))
+	}
+	if p.Message != "" {
+		fmt.Fprintln(w)
+		fmt.Fprintln(w, p.Message)
+	}
+
+	if len(p.Files) == 0 {
+		// not a parseable format-patch email: fall back to the raw body,
+		// same as the original unstructured renderer.
+		fmt.Fprintln(w)
+		fmt.Fprint(w, p.Body)
+		return
+	}
+
+	for _, file := range p.Files {
+		fmt.Fprintln(w)
+		fmt.Fprintln(w, underline("diff --git a/"+file.OldPath+" b/"+file.NewPath))
+		for _, hunk := range file.Hunks {
+			fmt.Fprintln(w, cyan(hunk.Header))
+			for _, line := range hunk.Lines {
+				switch line.Kind {
+				case Addition:
+					fmt.Fprintln(w, green("+"+line.Text))
+				case Deletion:
+					fmt.Fprintln(w, red("-"+line.Text))
+				default:
+					fmt.Fprintln(w, " "+line.Text)
+				}
+			}
+		}
+	}
+}
+
+func plainString(a ...any) string {
+	return fmt.Sprint(a...)
+}
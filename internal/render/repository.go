@@ -0,0 +1,75 @@
+package render
+
+import (
+	"encoding/json"
+	"fmt"
+	"strings"
+
+	"github.com/fatih/color"
+)
+
+// Repository is a NIP-34 repository announcement event, with its tags
+// pulled out into their named fields.
+type Repository struct {
+	ID          string   `json:"id"`
+	Author      string   `json:"author"` // npub
+	Name        string   `json:"name,omitempty"`
+	Description string   `json:"description,omitempty"`
+	Web         []string `json:"web,omitempty"`
+	Clone       []string `json:"clone,omitempty"`
+	Relays      []string `json:"relays,omitempty"`
+	Maintainers []string `json:"maintainers,omitempty"` // npubs
+}
+
+func WriteRepository(w *strings.Builder, r *Repository, format Format) error {
+	switch format {
+	case Plain:
+		writeRepositoryPlain(w, r, false)
+		return nil
+	case Color:
+		writeRepositoryPlain(w, r, true)
+		return nil
+	case JSON:
+		data, err := json.MarshalIndent(r, "", "  ")
+		if err != nil {
+			return fmt.Errorf("marshaling repository: %w", err)
+		}
+		w.Write(data)
+		w.WriteByte('\n')
+		return nil
+	default:
+		return fmt.Errorf("unsupported repository format %v", format)
+	}
+}
+
+func writeRepositoryPlain(w *strings.Builder, r *Repository, colorize bool) {
+	dim := plainString
+	bold := plainString
+	if colorize {
+		dim = color.New(color.Faint).Sprint
+		bold = color.New(color.Bold).Sprint
+	}
+
+	fmt.Fprintln(w)
+	fmt.Fprintln(w, dim("author: "+r.Author))
+	fmt.Fprintln(w, dim("id: "+r.ID))
+	if r.Name != "" {
+		fmt.Fprintln(w)
+		fmt.Fprintln(w, bold(r.Name))
+	}
+	if r.Description != "" {
+		fmt.Fprintln(w, r.Description)
+	}
+	if len(r.Web) > 0 {
+		fmt.Fprintln(w, dim("web: ")+strings.Join(r.Web, " "))
+	}
+	if len(r.Clone) > 0 {
+		fmt.Fprintln(w, dim("clone: ")+strings.Join(r.Clone, " "))
+	}
+	if len(r.Relays) > 0 {
+		fmt.Fprintln(w, dim("relays: ")+strings.Join(r.Relays, " "))
+	}
+	if len(r.Maintainers) > 0 {
+		fmt.Fprintln(w, dim("maintainers: ")+strings.Join(r.Maintainers, " "))
+	}
+}
@@ -0,0 +1,40 @@
+package sss
+
+import (
+	"fmt"
+
+	"github.com/btcsuite/btcd/btcutil/bech32"
+)
+
+// shardHRP is the bech32 human-readable part used for encoded shards.
+const shardHRP = "nshard"
+
+// EncodeShard bech32-encodes a shard as index || bytes under the
+// "nshard1" prefix, the same encoding style nostr uses for nsec/npub.
+func EncodeShard(s Shard) (string, error) {
+	data := append([]byte{s.Index}, s.Bytes...)
+	bits5, err := bech32.ConvertBits(data, 8, 5, true)
+	if err != nil {
+		return "", fmt.Errorf("converting shard to bech32: %w", err)
+	}
+	return bech32.Encode(shardHRP, bits5)
+}
+
+// DecodeShard parses a shard previously produced by EncodeShard.
+func DecodeShard(s string) (Shard, error) {
+	hrp, bits5, err := bech32.DecodeNoLimit(s)
+	if err != nil {
+		return Shard{}, fmt.Errorf("decoding shard: %w", err)
+	}
+	if hrp != shardHRP {
+		return Shard{}, fmt.Errorf("not a gitstr shard (expected %q prefix, got %q)", shardHRP+"1", hrp)
+	}
+	data, err := bech32.ConvertBits(bits5, 5, 8, false)
+	if err != nil {
+		return Shard{}, fmt.Errorf("converting shard from bech32: %w", err)
+	}
+	if len(data) < 2 {
+		return Shard{}, fmt.Errorf("shard is too short")
+	}
+	return Shard{Index: data[0], Bytes: data[1:]}, nil
+}
@@ -0,0 +1,112 @@
+// Package sss implements Shamir secret sharing over GF(256): a secret of
+// arbitrary length is split into n shards of which any k reconstruct it,
+// with every byte of the secret treated as the constant term of its own
+// degree-(k-1) polynomial.
+package sss
+
+import (
+	"crypto/rand"
+	"fmt"
+)
+
+// Shard is one piece of a split secret: Index (1..n, never 0) and the
+// evaluated byte for every byte of the original secret.
+type Shard struct {
+	Index byte
+	Bytes []byte
+}
+
+// Split breaks secret into shares shards of which any threshold
+// reconstruct it.
+func Split(secret []byte, threshold, shares int) ([]Shard, error) {
+	if threshold < 2 {
+		return nil, fmt.Errorf("threshold must be at least 2")
+	}
+	if shares < threshold {
+		return nil, fmt.Errorf("shares (%d) must be at least the threshold (%d)", shares, threshold)
+	}
+	if shares > 255 {
+		return nil, fmt.Errorf("shares must be at most 255")
+	}
+	if len(secret) == 0 {
+		return nil, fmt.Errorf("secret must not be empty")
+	}
+
+	// polys[i] holds the degree-(threshold-1) polynomial for secret[i]:
+	// coefficient 0 is the secret byte itself, the rest are random.
+	polys := make([][]byte, len(secret))
+	for i, b := range secret {
+		coeffs := make([]byte, threshold)
+		coeffs[0] = b
+		if _, err := rand.Read(coeffs[1:]); err != nil {
+			return nil, fmt.Errorf("generating random coefficients: %w", err)
+		}
+		polys[i] = coeffs
+	}
+
+	shards := make([]Shard, shares)
+	for s := 0; s < shares; s++ {
+		x := byte(s + 1)
+		bs := make([]byte, len(secret))
+		for i, coeffs := range polys {
+			bs[i] = gfEval(coeffs, x)
+		}
+		shards[s] = Shard{Index: x, Bytes: bs}
+	}
+	return shards, nil
+}
+
+// Combine reconstructs the secret from at least threshold shards, using
+// Lagrange interpolation at x=0 on each byte independently. It doesn't
+// know the original threshold: give it too few shards and it will
+// silently return the wrong secret, same as any Shamir scheme.
+func Combine(shards []Shard) ([]byte, error) {
+	if len(shards) < 2 {
+		return nil, fmt.Errorf("need at least 2 shards to reconstruct a secret")
+	}
+
+	size := len(shards[0].Bytes)
+	seen := map[byte]bool{}
+	for _, s := range shards {
+		if s.Index == 0 {
+			return nil, fmt.Errorf("invalid shard index 0")
+		}
+		if seen[s.Index] {
+			return nil, fmt.Errorf("duplicate shard index %d", s.Index)
+		}
+		seen[s.Index] = true
+		if len(s.Bytes) != size {
+			return nil, fmt.Errorf("shards disagree on secret length")
+		}
+	}
+
+	secret := make([]byte, size)
+	for i := 0; i < size; i++ {
+		secret[i] = lagrangeInterpolateZero(shards, i)
+	}
+	return secret, nil
+}
+
+// lagrangeInterpolateZero evaluates, at x=0, the unique polynomial that
+// passes through (shard.Index, shard.Bytes[byteIndex]) for every shard.
+func lagrangeInterpolateZero(shards []Shard, byteIndex int) byte {
+	result := byte(0)
+	for i, si := range shards {
+		xi := si.Index
+		yi := si.Bytes[byteIndex]
+
+		num := byte(1)
+		den := byte(1)
+		for j, sj := range shards {
+			if i == j {
+				continue
+			}
+			xj := sj.Index
+			// numerator: product of (0 - xj) = xj, since subtraction is XOR
+			num = gfMul(num, xj)
+			den = gfMul(den, gfAdd(xi, xj))
+		}
+		result = gfAdd(result, gfMul(yi, gfDiv(num, den)))
+	}
+	return result
+}
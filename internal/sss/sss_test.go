@@ -0,0 +1,84 @@
+package sss
+
+import (
+	"bytes"
+	"crypto/rand"
+	"testing"
+)
+
+func TestSplitCombineRoundTrip(t *testing.T) {
+	secret := make([]byte, 32)
+	if _, err := rand.Read(secret); err != nil {
+		t.Fatal(err)
+	}
+
+	shards, err := Split(secret, 3, 5)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(shards) != 5 {
+		t.Fatalf("got %d shards, want 5", len(shards))
+	}
+
+	// any 3 of the 5 shards should reconstruct the secret
+	combos := [][]int{{0, 1, 2}, {0, 2, 4}, {1, 3, 4}}
+	for _, combo := range combos {
+		subset := make([]Shard, len(combo))
+		for i, idx := range combo {
+			subset[i] = shards[idx]
+		}
+		got, err := Combine(subset)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if !bytes.Equal(got, secret) {
+			t.Fatalf("combo %v: reconstructed secret mismatch", combo)
+		}
+	}
+}
+
+func TestCombineRejectsDuplicateIndex(t *testing.T) {
+	shards, err := Split([]byte("0123456789abcdef0123456789abcdef"), 3, 5)
+	if err != nil {
+		t.Fatal(err)
+	}
+	_, err = Combine([]Shard{shards[0], shards[0], shards[1]})
+	if err == nil {
+		t.Fatal("expected an error for duplicate shard indices")
+	}
+}
+
+func TestBech32RoundTrip(t *testing.T) {
+	shards, err := Split([]byte("0123456789abcdef0123456789abcdef"), 3, 5)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, s := range shards {
+		encoded, err := EncodeShard(s)
+		if err != nil {
+			t.Fatal(err)
+		}
+		decoded, err := DecodeShard(encoded)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if decoded.Index != s.Index || !bytes.Equal(decoded.Bytes, s.Bytes) {
+			t.Fatalf("shard round trip mismatch for index %d", s.Index)
+		}
+	}
+}
+
+func TestSplitRejectsBadParameters(t *testing.T) {
+	cases := []struct {
+		threshold, shares int
+	}{
+		{1, 5},   // threshold too low
+		{4, 3},   // shares < threshold
+		{3, 256}, // shares too high
+	}
+	for _, c := range cases {
+		if _, err := Split([]byte("some secret bytes here........."), c.threshold, c.shares); err == nil {
+			t.Fatalf("expected error for threshold=%d shares=%d", c.threshold, c.shares)
+		}
+	}
+}
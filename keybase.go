@@ -0,0 +1,85 @@
+package gitstr
+
+import (
+	"encoding/json"
+	"fmt"
+	"strings"
+)
+
+// Keybase stores and retrieves named signing identities, so a user can
+// keep more than one key around (e.g. "work" vs "personal", switched
+// with --as) without them all living under the same git config value.
+type Keybase interface {
+	Get(name string) (Signer, error)
+	Set(name string, signer Signer) error
+	List() ([]string, error)
+	Delete(name string) error
+}
+
+// ErrIdentityNotFound is returned by a Keybase's Get when no key is
+// stored under the given name.
+var ErrIdentityNotFound = fmt.Errorf("no key stored for this identity")
+
+// defaultIdentity is used when the user doesn't pass --as.
+const defaultIdentity = "default"
+
+// keybaseFor resolves the Keybase implementation for the given backend
+// name, defaulting to the git-config backend used since the beginning.
+func keybaseFor(backend string) (Keybase, error) {
+	switch backend {
+	case "", "git-config":
+		return &gitConfigKeybase{}, nil
+	case "os":
+		return &osKeybase{}, nil
+	case "file":
+		return &fileKeybase{}, nil
+	case "memory":
+		return newMemoryKeybase(), nil
+	default:
+		return nil, fmt.Errorf("unknown keyring backend %q (want git-config, os, file or memory)", backend)
+	}
+}
+
+// asLocalSigner is the only Signer kind a Keybase can persist: ledger
+// and bunker identities already persist their own configuration (the
+// derivation path, the bunker URL) and hold no secret a keybase could
+// store.
+func asLocalSigner(signer Signer) (*localSigner, error) {
+	local, ok := signer.(*localSigner)
+	if !ok {
+		return nil, fmt.Errorf("only local secret keys can be stored in a keyring backend")
+	}
+	return local, nil
+}
+
+// localSignerEnvelope is the on-disk/keyring encoding for a localSigner
+// that also carries the mnemonic it was derived from. A bare secret
+// value (the historic format) stays raw, so existing stored keys keep
+// working unchanged; a signer with a mnemonic is wrapped in JSON so the
+// mnemonic rides along under whatever protection the backend already
+// gives the secret key.
+type localSignerEnvelope struct {
+	Sec      string `json:"sec"`
+	Mnemonic string `json:"mnemonic"`
+}
+
+func encodeLocalSigner(local *localSigner) string {
+	if local.mnemonic == "" {
+		return local.sec
+	}
+	data, err := json.Marshal(localSignerEnvelope{Sec: local.sec, Mnemonic: local.mnemonic})
+	if err != nil {
+		return local.sec
+	}
+	return string(data)
+}
+
+func signerFromStored(value string) Signer {
+	if strings.HasPrefix(value, "{") {
+		var env localSignerEnvelope
+		if err := json.Unmarshal([]byte(value), &env); err == nil && env.Sec != "" {
+			return &localSigner{sec: env.Sec, mnemonic: env.Mnemonic, encrypted: strings.HasPrefix(env.Sec, "ncryptsec1")}
+		}
+	}
+	return &localSigner{sec: value, encrypted: strings.HasPrefix(value, "ncryptsec1")}
+}
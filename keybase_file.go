@@ -0,0 +1,198 @@
+package gitstr
+
+import (
+	"crypto/rand"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+
+	"golang.org/x/crypto/nacl/secretbox"
+	"golang.org/x/crypto/scrypt"
+)
+
+const (
+	fileKeybaseScryptN = 1 << 15
+	fileKeybaseScryptR = 8
+	fileKeybaseScryptP = 1
+)
+
+// fileKeystore is the on-disk layout of ~/.config/gitstr/keys.json: one
+// scrypt salt for the whole file, and one NaCl secretbox per identity,
+// all sealed with the key derived from the user's master password.
+type fileKeystore struct {
+	Salt       string                       `json:"salt"`
+	Identities map[string]fileKeystoreEntry `json:"identities"`
+}
+
+type fileKeystoreEntry struct {
+	Nonce      string `json:"nonce"`
+	Ciphertext string `json:"ciphertext"`
+}
+
+// fileKeybase is an encrypted JSON keystore: every secret is sealed with
+// NaCl secretbox using a key derived from a master password via scrypt,
+// so the file is safe to keep even outside the repository.
+type fileKeybase struct{}
+
+func fileKeybasePath() (string, error) {
+	dir, err := os.UserConfigDir()
+	if err != nil {
+		return "", fmt.Errorf("finding config dir: %w", err)
+	}
+	return filepath.Join(dir, "gitstr", "keys.json"), nil
+}
+
+func loadFileKeystore() (*fileKeystore, error) {
+	path, err := fileKeybasePath()
+	if err != nil {
+		return nil, err
+	}
+	data, err := os.ReadFile(path)
+	if os.IsNotExist(err) {
+		salt := make([]byte, 16)
+		if _, err := rand.Read(salt); err != nil {
+			return nil, err
+		}
+		return &fileKeystore{
+			Salt:       base64.StdEncoding.EncodeToString(salt),
+			Identities: map[string]fileKeystoreEntry{},
+		}, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("reading %s: %w", path, err)
+	}
+	var ks fileKeystore
+	if err := json.Unmarshal(data, &ks); err != nil {
+		return nil, fmt.Errorf("parsing %s: %w", path, err)
+	}
+	if ks.Identities == nil {
+		ks.Identities = map[string]fileKeystoreEntry{}
+	}
+	return &ks, nil
+}
+
+func saveFileKeystore(ks *fileKeystore) error {
+	path, err := fileKeybasePath()
+	if err != nil {
+		return err
+	}
+	if err := os.MkdirAll(filepath.Dir(path), 0700); err != nil {
+		return fmt.Errorf("creating %s: %w", filepath.Dir(path), err)
+	}
+	data, err := json.MarshalIndent(ks, "", "  ")
+	if err != nil {
+		return err
+	}
+	return os.WriteFile(path, data, 0600)
+}
+
+func (ks *fileKeystore) deriveKey(password string) (*[32]byte, error) {
+	salt, err := base64.StdEncoding.DecodeString(ks.Salt)
+	if err != nil {
+		return nil, fmt.Errorf("decoding keystore salt: %w", err)
+	}
+	derived, err := scrypt.Key([]byte(password), salt, fileKeybaseScryptN, fileKeybaseScryptR, fileKeybaseScryptP, 32)
+	if err != nil {
+		return nil, fmt.Errorf("deriving key: %w", err)
+	}
+	var key [32]byte
+	copy(key[:], derived)
+	return &key, nil
+}
+
+func askMasterPassword() (string, error) {
+	return askPassword("master password for the gitstr file keystore: ", nil)
+}
+
+func (k *fileKeybase) Get(name string) (Signer, error) {
+	ks, err := loadFileKeystore()
+	if err != nil {
+		return nil, err
+	}
+	entry, ok := ks.Identities[name]
+	if !ok {
+		return nil, ErrIdentityNotFound
+	}
+
+	password, err := askMasterPassword()
+	if err != nil {
+		return nil, err
+	}
+	key, err := ks.deriveKey(password)
+	if err != nil {
+		return nil, err
+	}
+
+	nonce, err := base64.StdEncoding.DecodeString(entry.Nonce)
+	if err != nil {
+		return nil, fmt.Errorf("decoding nonce: %w", err)
+	}
+	ciphertext, err := base64.StdEncoding.DecodeString(entry.Ciphertext)
+	if err != nil {
+		return nil, fmt.Errorf("decoding ciphertext: %w", err)
+	}
+	var nonceArr [24]byte
+	copy(nonceArr[:], nonce)
+
+	plain, ok := secretbox.Open(nil, ciphertext, &nonceArr, key)
+	if !ok {
+		return nil, fmt.Errorf("wrong master password or corrupted keystore")
+	}
+	return signerFromStored(string(plain)), nil
+}
+
+func (k *fileKeybase) Set(name string, signer Signer) error {
+	local, err := asLocalSigner(signer)
+	if err != nil {
+		return err
+	}
+
+	ks, err := loadFileKeystore()
+	if err != nil {
+		return err
+	}
+
+	password, err := askMasterPassword()
+	if err != nil {
+		return err
+	}
+	key, err := ks.deriveKey(password)
+	if err != nil {
+		return err
+	}
+
+	var nonce [24]byte
+	if _, err := rand.Read(nonce[:]); err != nil {
+		return fmt.Errorf("generating nonce: %w", err)
+	}
+	ciphertext := secretbox.Seal(nil, []byte(encodeLocalSigner(local)), &nonce, key)
+
+	ks.Identities[name] = fileKeystoreEntry{
+		Nonce:      base64.StdEncoding.EncodeToString(nonce[:]),
+		Ciphertext: base64.StdEncoding.EncodeToString(ciphertext),
+	}
+	return saveFileKeystore(ks)
+}
+
+func (k *fileKeybase) List() ([]string, error) {
+	ks, err := loadFileKeystore()
+	if err != nil {
+		return nil, err
+	}
+	names := make([]string, 0, len(ks.Identities))
+	for name := range ks.Identities {
+		names = append(names, name)
+	}
+	return names, nil
+}
+
+func (k *fileKeybase) Delete(name string) error {
+	ks, err := loadFileKeystore()
+	if err != nil {
+		return err
+	}
+	delete(ks.Identities, name)
+	return saveFileKeystore(ks)
+}
@@ -0,0 +1,67 @@
+package gitstr
+
+import (
+	"fmt"
+	"strings"
+)
+
+// gitConfigKeybase is the original storage strategy: the secret key
+// lives in plaintext in the repository's local git config. It remains
+// the default for back-compat. The default identity keeps using the
+// historic `str.secretkey` key; named identities get their own
+// `str.identity.<name>.secretkey`.
+type gitConfigKeybase struct{}
+
+func gitConfigKeyFor(name string) string {
+	if name == "" || name == defaultIdentity {
+		return "str.secretkey"
+	}
+	return "str.identity." + name + ".secretkey"
+}
+
+func (k *gitConfigKeybase) Get(name string) (Signer, error) {
+	value, err := git("config", "--local", gitConfigKeyFor(name))
+	if err != nil || value == "" {
+		return nil, ErrIdentityNotFound
+	}
+	return signerFromStored(value), nil
+}
+
+func (k *gitConfigKeybase) Set(name string, signer Signer) error {
+	local, err := asLocalSigner(signer)
+	if err != nil {
+		return err
+	}
+	if !local.encrypted && !strings.HasPrefix(local.sec, "ncryptsec1") {
+		if pubkey, _ := git("config", "--local", agentPubkeyConfigKeyFor(name)); pubkey != "" {
+			return fmt.Errorf("refusing to write a plaintext secret key to git config while an agent key is configured for %q; use an encrypted (ncryptsec1) key or `str agent add` instead", name)
+		}
+	}
+	_, err = git("config", "--local", gitConfigKeyFor(name), encodeLocalSigner(local))
+	return err
+}
+
+func (k *gitConfigKeybase) List() ([]string, error) {
+	names := []string{}
+	if value, _ := git("config", "--local", "str.secretkey"); value != "" {
+		names = append(names, defaultIdentity)
+	}
+	out, _ := git("config", "--local", "--get-regexp", `^str\.identity\..*\.secretkey$`)
+	for _, line := range strings.Split(out, "\n") {
+		key, _, ok := strings.Cut(line, " ")
+		if !ok {
+			continue
+		}
+		key = strings.TrimPrefix(key, "str.identity.")
+		key = strings.TrimSuffix(key, ".secretkey")
+		if key != "" {
+			names = append(names, key)
+		}
+	}
+	return names, nil
+}
+
+func (k *gitConfigKeybase) Delete(name string) error {
+	_, err := git("config", "--local", "--unset", gitConfigKeyFor(name))
+	return err
+}
@@ -0,0 +1,52 @@
+package gitstr
+
+import "sync"
+
+// memoryKeybase keeps identities in process memory only. It exists for
+// tests: nothing is ever written to disk or to any OS service.
+type memoryKeybase struct {
+	mu      sync.Mutex
+	secrets map[string]string
+}
+
+func newMemoryKeybase() *memoryKeybase {
+	return &memoryKeybase{secrets: map[string]string{}}
+}
+
+func (k *memoryKeybase) Get(name string) (Signer, error) {
+	k.mu.Lock()
+	defer k.mu.Unlock()
+	value, ok := k.secrets[name]
+	if !ok {
+		return nil, ErrIdentityNotFound
+	}
+	return signerFromStored(value), nil
+}
+
+func (k *memoryKeybase) Set(name string, signer Signer) error {
+	local, err := asLocalSigner(signer)
+	if err != nil {
+		return err
+	}
+	k.mu.Lock()
+	defer k.mu.Unlock()
+	k.secrets[name] = encodeLocalSigner(local)
+	return nil
+}
+
+func (k *memoryKeybase) List() ([]string, error) {
+	k.mu.Lock()
+	defer k.mu.Unlock()
+	names := make([]string, 0, len(k.secrets))
+	for name := range k.secrets {
+		names = append(names, name)
+	}
+	return names, nil
+}
+
+func (k *memoryKeybase) Delete(name string) error {
+	k.mu.Lock()
+	defer k.mu.Unlock()
+	delete(k.secrets, name)
+	return nil
+}
@@ -0,0 +1,82 @@
+package gitstr
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/zalando/go-keyring"
+)
+
+const osKeybaseService = "gitstr"
+
+// osKeybase stores secrets in the platform-native credential store
+// (macOS Keychain, Windows Credential Manager or the freedesktop Secret
+// Service on Linux) instead of plaintext git config.
+//
+// The OS keyring has no "list all entries for this service" API, so the
+// set of registered identity names is tracked separately in git config.
+type osKeybase struct{}
+
+func osKeybaseIndexKey() string {
+	return "str.keyring.os.identities"
+}
+
+func (k *osKeybase) identities() []string {
+	value, _ := git("config", "--local", "--get-all", osKeybaseIndexKey())
+	if value == "" {
+		return nil
+	}
+	return strings.Split(value, "\n")
+}
+
+func (k *osKeybase) Get(name string) (Signer, error) {
+	value, err := keyring.Get(osKeybaseService, name)
+	if err != nil {
+		if err == keyring.ErrNotFound {
+			return nil, ErrIdentityNotFound
+		}
+		return nil, fmt.Errorf("reading from os keyring: %w", err)
+	}
+	return signerFromStored(value), nil
+}
+
+func (k *osKeybase) Set(name string, signer Signer) error {
+	local, err := asLocalSigner(signer)
+	if err != nil {
+		return err
+	}
+	if err := keyring.Set(osKeybaseService, name, encodeLocalSigner(local)); err != nil {
+		return fmt.Errorf("writing to os keyring: %w", err)
+	}
+	for _, existing := range k.identities() {
+		if existing == name {
+			return nil
+		}
+	}
+	_, err = git("config", "--local", "--add", osKeybaseIndexKey(), name)
+	return err
+}
+
+func (k *osKeybase) List() ([]string, error) {
+	return k.identities(), nil
+}
+
+func (k *osKeybase) Delete(name string) error {
+	if err := keyring.Delete(osKeybaseService, name); err != nil && err != keyring.ErrNotFound {
+		return fmt.Errorf("deleting from os keyring: %w", err)
+	}
+
+	remaining := make([]string, 0)
+	for _, existing := range k.identities() {
+		if existing != name {
+			remaining = append(remaining, existing)
+		}
+	}
+	git("config", "--local", "--unset-all", osKeybaseIndexKey())
+	for _, existing := range remaining {
+		if _, err := git("config", "--local", "--add", osKeybaseIndexKey(), existing); err != nil {
+			return err
+		}
+	}
+	return nil
+}
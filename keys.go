@@ -0,0 +1,382 @@
+package gitstr
+
+import (
+	"context"
+	"encoding/hex"
+	"fmt"
+
+	"github.com/Anderson-Juhasc/gitstr/internal/bip39"
+	"github.com/Anderson-Juhasc/gitstr/internal/sss"
+	"github.com/nbd-wtf/go-nostr"
+	"github.com/nbd-wtf/go-nostr/nip19"
+	"github.com/urfave/cli/v3"
+)
+
+// defaultLedgerPath is the derivation path used when the user doesn't
+// pick one explicitly, following NIP-06's account-based layout.
+const defaultLedgerPath = "m/44'/1237'/0'/0/0"
+
+func KeysCommand() *cli.Command {
+	return &cli.Command{
+		Name:  "keys",
+		Usage: "manage the secret keys gitstr signs events with",
+		Commands: []*cli.Command{
+			addKeysCommand(),
+			generateKeysCommand(),
+			recoverKeysCommand(),
+			exportMnemonicCommand(),
+			listKeysCommand(),
+			deleteKeysCommand(),
+			backupKeysCommand(),
+			restoreKeysCommand(),
+		},
+	}
+}
+
+// identityFlags are shared by every subcommand that reads or writes a
+// secret key through a Keybase, so the same repository can juggle more
+// than one identity (e.g. --as work vs --as personal).
+func identityFlags() []cli.Flag {
+	return []cli.Flag{
+		&cli.StringFlag{Name: "as", Usage: "identity name", Value: defaultIdentity},
+		&cli.StringFlag{Name: "keyring-backend", Usage: "git-config, os, file or memory"},
+	}
+}
+
+func identityAndKeybase(c *cli.Command) (string, Keybase, error) {
+	identity := c.String("as")
+	if identity == "" {
+		identity = defaultIdentity
+	}
+	backend := c.String("keyring-backend")
+	if backend == "" {
+		backend, _ = git("config", "--local", "str.keyring.backend")
+	}
+	kb, err := keybaseFor(backend)
+	return identity, kb, err
+}
+
+func addKeysCommand() *cli.Command {
+	return &cli.Command{
+		Name:  "add",
+		Usage: "register a signing key for this repository",
+		Flags: []cli.Flag{
+			&cli.BoolFlag{Name: "ledger", Usage: "use a Ledger hardware wallet"},
+			&cli.StringFlag{Name: "path", Usage: "BIP-32 derivation path", Value: defaultLedgerPath},
+		},
+		Action: func(ctx context.Context, c *cli.Command) error {
+			if !c.Bool("ledger") {
+				return fmt.Errorf("'str keys add' currently only supports --ledger")
+			}
+
+			path := c.String("path")
+			pubkey, err := ledgerGetPublicKey(path, true)
+			if err != nil {
+				return fmt.Errorf("reading public key from ledger: %w", err)
+			}
+
+			npub, err := nip19.EncodePublicKey(pubkey)
+			if err != nil {
+				return fmt.Errorf("encoding npub: %w", err)
+			}
+			fmt.Printf("confirm this is the key shown on your device: %s\n", npub)
+			if !confirm("use this key? ") {
+				return fmt.Errorf("aborted")
+			}
+
+			if _, err := git("config", "--local", "str.ledger", "true"); err != nil {
+				return fmt.Errorf("saving ledger config: %w", err)
+			}
+			if _, err := git("config", "--local", "str.ledger.path", path); err != nil {
+				return fmt.Errorf("saving ledger path: %w", err)
+			}
+
+			fmt.Printf("ledger key registered at path %s\n", path)
+			return nil
+		},
+	}
+}
+
+func generateKeysCommand() *cli.Command {
+	return &cli.Command{
+		Name:  "generate",
+		Usage: "generate a new secret key from a fresh BIP-39 mnemonic",
+		Flags: append(identityFlags(),
+			&cli.IntFlag{Name: "words", Usage: "mnemonic length, 12 or 24", Value: 24},
+			&cli.IntFlag{Name: "account", Usage: "NIP-06 account index", Value: 0},
+			&cli.BoolFlag{Name: "passphrase", Usage: "add an extra BIP-39 passphrase"},
+		),
+		Action: func(ctx context.Context, c *cli.Command) error {
+			bits := 256
+			if c.Int("words") == 12 {
+				bits = 128
+			} else if c.Int("words") != 24 {
+				return fmt.Errorf("--words must be 12 or 24")
+			}
+
+			entropy, err := bip39.NewEntropy(bits)
+			if err != nil {
+				return err
+			}
+			mnemonic, err := bip39.EntropyToMnemonic(entropy)
+			if err != nil {
+				return err
+			}
+
+			fmt.Println("write down your mnemonic phrase, it's the only backup of your key:")
+			fmt.Println("\n" + mnemonic + "\n")
+
+			return deriveAndStore(c, mnemonic)
+		},
+	}
+}
+
+func recoverKeysCommand() *cli.Command {
+	return &cli.Command{
+		Name:  "recover",
+		Usage: "recover a secret key from an existing BIP-39 mnemonic",
+		Flags: append(identityFlags(),
+			&cli.IntFlag{Name: "account", Usage: "NIP-06 account index", Value: 0},
+			&cli.BoolFlag{Name: "passphrase", Usage: "the mnemonic has an extra BIP-39 passphrase"},
+		),
+		Action: func(ctx context.Context, c *cli.Command) error {
+			mnemonic, err := ask("input your 12 or 24-word mnemonic: ", "", func(answer string) bool {
+				return !bip39.IsMnemonic(answer)
+			})
+			if err != nil {
+				return err
+			}
+
+			return deriveAndStore(c, mnemonic)
+		},
+	}
+}
+
+func exportMnemonicCommand() *cli.Command {
+	return &cli.Command{
+		Name:  "export-mnemonic",
+		Usage: "show the mnemonic the current key was derived from, if any",
+		Flags: identityFlags(),
+		Action: func(ctx context.Context, c *cli.Command) error {
+			identity, kb, err := identityAndKeybase(c)
+			if err != nil {
+				return err
+			}
+			signer, err := kb.Get(identity)
+			if err != nil {
+				return fmt.Errorf("loading %q: %w", identity, err)
+			}
+			local, err := asLocalSigner(signer)
+			if err != nil {
+				return err
+			}
+			if local.mnemonic == "" {
+				return fmt.Errorf("no mnemonic is stored for this identity")
+			}
+			fmt.Println(local.mnemonic)
+			return nil
+		},
+	}
+}
+
+// deriveAndStore asks for an optional passphrase, derives the nostr
+// secret key for the given mnemonic and stores it, together with the
+// mnemonic itself, under the configured keyring backend and identity,
+// so the mnemonic gets exactly the same protection as the key it was
+// derived from.
+func deriveAndStore(c *cli.Command, mnemonic string) error {
+	passphrase := ""
+	if c.Bool("passphrase") {
+		var err error
+		passphrase, err = askPassword("bip-39 passphrase: ", nil)
+		if err != nil {
+			return err
+		}
+	}
+
+	sec, err := deriveNostrKey(mnemonic, passphrase, c.Int("account"))
+	if err != nil {
+		return err
+	}
+
+	pubkey, err := nostr.GetPublicKey(sec)
+	if err != nil {
+		return fmt.Errorf("deriving public key: %w", err)
+	}
+	npub, err := nip19.EncodePublicKey(pubkey)
+	if err != nil {
+		return err
+	}
+
+	identity, kb, err := identityAndKeybase(c)
+	if err != nil {
+		return err
+	}
+	if err := kb.Set(identity, &localSigner{sec: sec, mnemonic: mnemonic}); err != nil {
+		return fmt.Errorf("storing secret key: %w", err)
+	}
+
+	fmt.Printf("key stored for %s\n", npub)
+	return nil
+}
+
+func listKeysCommand() *cli.Command {
+	return &cli.Command{
+		Name:  "list",
+		Usage: "list the identities registered in a keyring backend",
+		Flags: identityFlags(),
+		Action: func(ctx context.Context, c *cli.Command) error {
+			_, kb, err := identityAndKeybase(c)
+			if err != nil {
+				return err
+			}
+			names, err := kb.List()
+			if err != nil {
+				return err
+			}
+			for _, name := range names {
+				fmt.Println(name)
+			}
+			return nil
+		},
+	}
+}
+
+func deleteKeysCommand() *cli.Command {
+	return &cli.Command{
+		Name:  "delete",
+		Usage: "remove an identity from a keyring backend",
+		Flags: identityFlags(),
+		Action: func(ctx context.Context, c *cli.Command) error {
+			identity, kb, err := identityAndKeybase(c)
+			if err != nil {
+				return err
+			}
+			return kb.Delete(identity)
+		},
+	}
+}
+
+// backupShardFlags are the flags of `keys backup`. `keys restore`
+// declares its own --threshold flag with the same default of 3, so a
+// restore defaults to expecting what a default backup produced.
+func backupShardFlags() []cli.Flag {
+	return []cli.Flag{
+		&cli.IntFlag{Name: "threshold", Usage: "shards required to reconstruct the key", Value: 3},
+		&cli.IntFlag{Name: "shares", Usage: "total shards to produce", Value: 5},
+	}
+}
+
+func backupKeysCommand() *cli.Command {
+	return &cli.Command{
+		Name:  "backup",
+		Usage: "split a secret key into shards for disaster recovery (Shamir secret sharing)",
+		Flags: append(identityFlags(), backupShardFlags()...),
+		Action: func(ctx context.Context, c *cli.Command) error {
+			identity, kb, err := identityAndKeybase(c)
+			if err != nil {
+				return err
+			}
+			signer, err := kb.Get(identity)
+			if err != nil {
+				return fmt.Errorf("loading %q: %w", identity, err)
+			}
+			local, err := asLocalSigner(signer)
+			if err != nil {
+				return err
+			}
+			sec, err := local.decrypted()
+			if err != nil {
+				return err
+			}
+			secret, err := hex.DecodeString(sec)
+			if err != nil {
+				return fmt.Errorf("invalid secret key: %w", err)
+			}
+
+			threshold, shares := c.Int("threshold"), c.Int("shares")
+			shards, err := sss.Split(secret, threshold, shares)
+			if err != nil {
+				return err
+			}
+
+			fmt.Printf("any %d of these %d shards reconstruct the key for %q. store each one somewhere separate (paper, a different machine, a USB stick):\n\n", threshold, shares, identity)
+			for _, shard := range shards {
+				encoded, err := sss.EncodeShard(shard)
+				if err != nil {
+					return err
+				}
+				fmt.Printf("shard %d/%d: %s\n", shard.Index, shares, encoded)
+			}
+			return nil
+		},
+	}
+}
+
+func restoreKeysCommand() *cli.Command {
+	return &cli.Command{
+		Name:  "restore",
+		Usage: "reconstruct a secret key from backup shards and store it",
+		Flags: append(identityFlags(),
+			&cli.IntFlag{Name: "threshold", Usage: "shards required to reconstruct the key", Value: 3},
+		),
+		Action: func(ctx context.Context, c *cli.Command) error {
+			threshold := c.Int("threshold")
+
+			shards := make([]sss.Shard, 0, threshold)
+			seen := map[byte]bool{}
+			for len(shards) < threshold {
+				answer, err := ask(fmt.Sprintf("shard %d/%d (nshard1...): ", len(shards)+1, threshold), "", func(answer string) bool {
+					shard, err := sss.DecodeShard(answer)
+					if err != nil {
+						fmt.Println(err)
+						return true
+					}
+					if seen[shard.Index] {
+						fmt.Println("that shard was already entered")
+						return true
+					}
+					return false
+				})
+				if err != nil {
+					return err
+				}
+				shard, err := sss.DecodeShard(answer)
+				if err != nil {
+					return err
+				}
+				seen[shard.Index] = true
+				shards = append(shards, shard)
+			}
+
+			secret, err := sss.Combine(shards)
+			if err != nil {
+				return fmt.Errorf("reconstructing key: %w", err)
+			}
+			sec := hex.EncodeToString(secret)
+			if ok := nostr.IsValid32ByteHex(sec); !ok {
+				return fmt.Errorf("reconstructed key is invalid, check the shards and threshold")
+			}
+
+			pubkey, err := nostr.GetPublicKey(sec)
+			if err != nil {
+				return fmt.Errorf("deriving public key: %w", err)
+			}
+			npub, err := nip19.EncodePublicKey(pubkey)
+			if err != nil {
+				return err
+			}
+
+			identity, kb, err := identityAndKeybase(c)
+			if err != nil {
+				return err
+			}
+			if err := kb.Set(identity, &localSigner{sec: sec}); err != nil {
+				return fmt.Errorf("storing secret key: %w", err)
+			}
+
+			fmt.Printf("key restored for %s\n", npub)
+			return nil
+		},
+	}
+}
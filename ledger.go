@@ -0,0 +1,228 @@
+package gitstr
+
+import (
+	"encoding/binary"
+	"encoding/hex"
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/karalabe/hid"
+	"github.com/nbd-wtf/go-nostr"
+)
+
+const (
+	ledgerVendorID = 0x2c97
+
+	ledgerCLA            = 0xd4
+	ledgerInsGetPubkey   = 0x02
+	ledgerInsSignEvent   = 0x08
+	ledgerP1NoConfirm    = 0x00
+	ledgerP1Confirm      = 0x01
+	ledgerChannelID      = 0x0101
+	ledgerHIDBufferSize  = 64
+	ledgerHardenedOffset = 0x80000000
+)
+
+// ledgerSigner signs nostr events on a connected Ledger device, asking
+// the user to confirm each event on-screen before it produces a
+// signature.
+type ledgerSigner struct {
+	path string
+}
+
+// parseDerivationPath turns "m/44'/1237'/0'/0/0" into its uint32
+// components, with the BIP-32 hardened bit set for indexes written
+// with a trailing "'" (or "h").
+func parseDerivationPath(path string) ([]uint32, error) {
+	parts := strings.Split(path, "/")
+	if len(parts) == 0 || parts[0] != "m" {
+		return nil, fmt.Errorf("derivation path must start with \"m/\"")
+	}
+
+	indexes := make([]uint32, 0, len(parts)-1)
+	for _, part := range parts[1:] {
+		hardened := false
+		if strings.HasSuffix(part, "'") || strings.HasSuffix(part, "h") {
+			hardened = true
+			part = part[:len(part)-1]
+		}
+		n, err := strconv.ParseUint(part, 10, 32)
+		if err != nil {
+			return nil, fmt.Errorf("invalid path segment %q: %w", part, err)
+		}
+		index := uint32(n)
+		if hardened {
+			index += ledgerHardenedOffset
+		}
+		indexes = append(indexes, index)
+	}
+	return indexes, nil
+}
+
+// openLedger finds the first connected Ledger device.
+func openLedger() (*hid.Device, error) {
+	infos := hid.Enumerate(ledgerVendorID, 0)
+	if len(infos) == 0 {
+		return nil, fmt.Errorf("no ledger device found, make sure it's connected and unlocked")
+	}
+	return infos[0].Open()
+}
+
+// ledgerExchange wraps an APDU in the Ledger HID transport framing
+// (channel id, command tag, sequence number, then the data split into
+// 64-byte packets) and returns the unwrapped response.
+func ledgerExchange(dev *hid.Device, apdu []byte) ([]byte, error) {
+	seq := uint16(0)
+	remaining := apdu
+	for {
+		buf := make([]byte, ledgerHIDBufferSize+1) // +1 report id
+		offset := 1
+		copy(buf[offset:], []byte{byte(ledgerChannelID >> 8), byte(ledgerChannelID & 0xff)})
+		offset += 2
+		buf[offset] = 0x05
+		offset++
+		binary.BigEndian.PutUint16(buf[offset:offset+2], seq)
+		offset += 2
+		if seq == 0 {
+			binary.BigEndian.PutUint16(buf[offset:offset+2], uint16(len(apdu)))
+			offset += 2
+		}
+		n := copy(buf[offset:], remaining)
+		remaining = remaining[n:]
+		if _, err := dev.Write(buf); err != nil {
+			return nil, fmt.Errorf("writing to ledger: %w", err)
+		}
+		seq++
+		if len(remaining) == 0 {
+			break
+		}
+	}
+
+	resp := make([]byte, ledgerHIDBufferSize+1)
+	full := []byte{}
+	expected := -1
+	for expected < 0 || len(full) < expected {
+		n, err := dev.Read(resp)
+		if err != nil {
+			return nil, fmt.Errorf("reading from ledger: %w", err)
+		}
+		if n < 7 {
+			continue
+		}
+		offset := 5
+		if expected < 0 {
+			expected = int(binary.BigEndian.Uint16(resp[offset : offset+2]))
+			offset += 2
+		}
+		full = append(full, resp[offset:n]...)
+	}
+	if len(full) < 2 {
+		return nil, fmt.Errorf("short response from ledger")
+	}
+	sw := binary.BigEndian.Uint16(full[len(full)-2:])
+	if sw != 0x9000 {
+		return nil, fmt.Errorf("ledger returned error status 0x%04x", sw)
+	}
+	return full[:len(full)-2], nil
+}
+
+// buildPathData encodes a derivation path the way the Ledger nostr app
+// expects it: one byte with the number of indexes, then each index as
+// big-endian uint32.
+func buildPathData(indexes []uint32) []byte {
+	data := make([]byte, 1+4*len(indexes))
+	data[0] = byte(len(indexes))
+	for i, idx := range indexes {
+		binary.BigEndian.PutUint32(data[1+4*i:], idx)
+	}
+	return data
+}
+
+// buildAPDU assembles a command APDU, using the extended-length Lc
+// encoding (a 0x00 byte followed by a big-endian uint16) when data
+// doesn't fit in the classic one-byte length field.
+func buildAPDU(cla, ins, p1, p2 byte, data []byte) ([]byte, error) {
+	if len(data) > 0xffff {
+		return nil, fmt.Errorf("apdu data too large: %d bytes", len(data))
+	}
+	apdu := []byte{cla, ins, p1, p2}
+	if len(data) <= 0xff {
+		apdu = append(apdu, byte(len(data)))
+	} else {
+		apdu = append(apdu, 0x00, byte(len(data)>>8), byte(len(data)))
+	}
+	return append(apdu, data...), nil
+}
+
+func ledgerGetPublicKey(path string, confirm bool) (string, error) {
+	indexes, err := parseDerivationPath(path)
+	if err != nil {
+		return "", err
+	}
+	dev, err := openLedger()
+	if err != nil {
+		return "", err
+	}
+	defer dev.Close()
+
+	p1 := byte(ledgerP1NoConfirm)
+	if confirm {
+		p1 = ledgerP1Confirm
+	}
+	data := buildPathData(indexes)
+	apdu, err := buildAPDU(ledgerCLA, ledgerInsGetPubkey, p1, 0x00, data)
+	if err != nil {
+		return "", err
+	}
+	resp, err := ledgerExchange(dev, apdu)
+	if err != nil {
+		return "", fmt.Errorf("asking ledger for public key: %w", err)
+	}
+	return hex.EncodeToString(resp), nil
+}
+
+func ledgerSignEvent(path string, evt *nostr.Event) error {
+	indexes, err := parseDerivationPath(path)
+	if err != nil {
+		return err
+	}
+
+	// The device must sign the exact serialization that ends up on the
+	// wire, so the event's final pubkey and id have to be set first:
+	// Serialize embeds PubKey, and GetID hashes that same serialization.
+	evt.PubKey, err = ledgerGetPublicKey(path, false)
+	if err != nil {
+		return err
+	}
+	evt.ID = evt.GetID()
+
+	dev, err := openLedger()
+	if err != nil {
+		return err
+	}
+	defer dev.Close()
+
+	serialized := evt.Serialize()
+
+	data := append(buildPathData(indexes), serialized...)
+	apdu, err := buildAPDU(ledgerCLA, ledgerInsSignEvent, ledgerP1Confirm, 0x00, data)
+	if err != nil {
+		return err
+	}
+	resp, err := ledgerExchange(dev, apdu)
+	if err != nil {
+		return fmt.Errorf("signing on ledger (did you confirm the event on-device?): %w", err)
+	}
+
+	evt.Sig = hex.EncodeToString(resp)
+	return nil
+}
+
+func (s *ledgerSigner) PublicKey() (string, error) {
+	return ledgerGetPublicKey(s.path, false)
+}
+
+func (s *ledgerSigner) SignEvent(evt *nostr.Event) error {
+	return ledgerSignEvent(s.path, evt)
+}
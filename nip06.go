@@ -0,0 +1,35 @@
+package gitstr
+
+import (
+	"encoding/hex"
+	"fmt"
+
+	"github.com/Anderson-Juhasc/gitstr/internal/bip32"
+	"github.com/Anderson-Juhasc/gitstr/internal/bip39"
+)
+
+// deriveNostrKey turns a BIP-39 mnemonic sentence (plus optional
+// passphrase) into a nostr secret key, following NIP-06:
+// PBKDF2 seed -> BIP-32 m/44'/1237'/<account>'/0/0 -> raw private key.
+func deriveNostrKey(mnemonic, passphrase string, account int) (string, error) {
+	if _, err := bip39.MnemonicToEntropy(mnemonic); err != nil {
+		return "", fmt.Errorf("invalid mnemonic: %w", err)
+	}
+
+	seed := bip39.SeedFromMnemonic(mnemonic, passphrase)
+	master, err := bip32.NewMasterKey(seed)
+	if err != nil {
+		return "", fmt.Errorf("deriving master key: %w", err)
+	}
+
+	indexes, err := parseDerivationPath(fmt.Sprintf("m/44'/1237'/%d'/0/0", account))
+	if err != nil {
+		return "", err
+	}
+	child, err := master.DerivePath(indexes)
+	if err != nil {
+		return "", fmt.Errorf("deriving nostr key: %w", err)
+	}
+
+	return hex.EncodeToString(child.Private), nil
+}
@@ -0,0 +1,72 @@
+package gitstr
+
+import (
+	"context"
+
+	"github.com/nbd-wtf/go-nostr"
+	"github.com/nbd-wtf/go-nostr/nip46"
+)
+
+// Signer abstracts over the different ways gitstr can produce a valid
+// signature for a nostr event: a local secret key (optionally encrypted
+// as ncryptsec1), a remote NIP-46 bunker, a hardware wallet, or a key
+// held by a running gitstr agent.
+type Signer interface {
+	PublicKey() (string, error)
+	SignEvent(evt *nostr.Event) error
+}
+
+// localSigner signs with a secret key held in process memory. If the key
+// was supplied as ncryptsec1 it is decrypted lazily, on the first call
+// to PublicKey or SignEvent, so merely constructing or storing the
+// signer never prompts for a password. mnemonic is set when the key was derived from a BIP-39
+// phrase, so it can be stored and recovered alongside the key under
+// whatever protection the configured keyring backend gives the key
+// itself.
+type localSigner struct {
+	sec       string
+	mnemonic  string
+	encrypted bool
+}
+
+func (s *localSigner) decrypted() (string, error) {
+	if !s.encrypted {
+		return s.sec, nil
+	}
+	sec, err := promptDecrypt(s.sec)
+	if err != nil {
+		return "", err
+	}
+	s.sec = sec
+	s.encrypted = false
+	return sec, nil
+}
+
+func (s *localSigner) PublicKey() (string, error) {
+	sec, err := s.decrypted()
+	if err != nil {
+		return "", err
+	}
+	return nostr.GetPublicKey(sec)
+}
+
+func (s *localSigner) SignEvent(evt *nostr.Event) error {
+	sec, err := s.decrypted()
+	if err != nil {
+		return err
+	}
+	return evt.Sign(sec)
+}
+
+// bunkerSigner delegates signing to a remote NIP-46 bunker.
+type bunkerSigner struct {
+	bunker *nip46.BunkerClient
+}
+
+func (s *bunkerSigner) PublicKey() (string, error) {
+	return s.bunker.GetPublicKey(context.Background())
+}
+
+func (s *bunkerSigner) SignEvent(evt *nostr.Event) error {
+	return s.bunker.SignEvent(context.Background(), evt)
+}